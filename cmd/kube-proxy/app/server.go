@@ -44,6 +44,7 @@ import (
 	"k8s.io/kubernetes/pkg/util/oom"
 
 	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
@@ -258,6 +259,7 @@ func (s *ProxyServer) Run() error {
 
 	// Start up Healthz service if requested
 	if s.Config.HealthzPort > 0 {
+		http.Handle("/metrics", prometheus.Handler())
 		go util.Until(func() {
 			err := http.ListenAndServe(s.Config.HealthzBindAddress.String()+":"+strconv.Itoa(s.Config.HealthzPort), nil)
 			if err != nil {
@@ -0,0 +1,222 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// metricsgrab scrapes the metrics endpoints of a live cluster's control
+// plane components using pkg/metrics -- the same grabbing and parsing code
+// the e2e suite uses -- and writes one snapshot file per component to an
+// output directory, so operators debugging a cluster don't need to reach
+// for curl and hand-parse Prometheus text format.
+//
+// Given --compare-old and --compare-new, it skips grabbing entirely and
+// instead diffs two previously written snapshot directories using
+// pkg/metrics/compare, printing added/removed/changed series.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	client "k8s.io/kubernetes/pkg/client/unversioned"
+	"k8s.io/kubernetes/pkg/client/unversioned/clientcmd"
+	"k8s.io/kubernetes/pkg/metrics"
+	"k8s.io/kubernetes/pkg/metrics/compare"
+	"k8s.io/kubernetes/pkg/util/sets"
+
+	"github.com/golang/glog"
+	flag "github.com/spf13/pflag"
+)
+
+var (
+	kubeconfig = flag.String("kubeconfig", "", "Path to a kubeconfig file.")
+	master     = flag.String("master", "", "The address of the Kubernetes API server. Overrides any value in --kubeconfig.")
+	context    = flag.String("context", "", "Name of the kubeconfig context to scrape through, e.g. to target one HA master directly instead of the load-balanced endpoint. If empty, the current context is used.")
+	components = flag.String("components", "apiserver,scheduler,controller-manager,kubelets", "Comma-separated list of components to grab metrics from.")
+	outputDir  = flag.String("output-dir", ".", "Directory to write one metrics snapshot file per component to.")
+
+	dropZeroSamples = flag.Bool("drop-zero-samples", false, "Drop zero-valued samples from written snapshots, shrinking artifact size and diff noise at the cost of losing the exact label permutations a component reported as zero.")
+
+	kubectlProxyAddress = flag.String("kubectl-proxy-address", "", "Address of a locally running 'kubectl proxy' (e.g. http://127.0.0.1:8001) to scrape metrics through, for clusters where only kubectl access is configured.")
+	parallelism         = flag.Int("parallelism", metrics.DefaultMetricsGrabberOptions().Parallelism, "Number of kubelets to scrape concurrently.")
+
+	compareOld           = flag.String("compare-old", "", "Directory of snapshots from a previous grab. If set with --compare-new, diffs them instead of grabbing.")
+	compareNew           = flag.String("compare-new", "", "Directory of snapshots from a later grab, to diff against --compare-old.")
+	noiseThreshold       = flag.Float64("noise-threshold", 0, "Minimum absolute value delta for a series to be reported as changed.")
+	ignoreRuntimeMetrics = flag.Bool("ignore-runtime-metrics", true, "Exclude the standard Go/process metric families from the comparison.")
+	renameRulesFile      = flag.String("rename-rules", "", "Path to a JSON file of {\"metrics\":{\"old\":\"new\"},\"labels\":{\"old\":\"new\"}} renames to normalize before comparing, for diffing snapshots taken across a version that renamed metrics or labels.")
+)
+
+// loadRenameRules reads the JSON-encoded compare.RenameRules at path, or
+// returns the zero value if path is empty.
+func loadRenameRules(path string) (compare.RenameRules, error) {
+	var rules compare.RenameRules
+	if path == "" {
+		return rules, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return rules, err
+	}
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return rules, fmt.Errorf("parsing rename rules %v: %v", path, err)
+	}
+	return rules, nil
+}
+
+// writeSnapshot writes snapshot in both the human-readable .metrics format
+// and, since model.Sample already supports JSON, the .json format
+// pkg/metrics/compare's LoadSnapshot can read back in.
+func writeSnapshot(name string, snapshot metrics.Metrics) error {
+	if *dropZeroSamples {
+		snapshot = snapshot.DropZeroSamples()
+	}
+	path := filepath.Join(*outputDir, name+".metrics")
+	if err := ioutil.WriteFile(path, []byte(snapshot.String()), 0644); err != nil {
+		return err
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(*outputDir, name+".json"), data, 0644)
+}
+
+func runCompare() {
+	rules, err := loadRenameRules(*renameRulesFile)
+	if err != nil {
+		glog.Fatalf("Error loading rename rules: %v", err)
+	}
+	opts := compare.Options{NoiseThreshold: *noiseThreshold, IgnoreRuntimeMetrics: *ignoreRuntimeMetrics, Rewrite: rules}
+	diffs, skipped, err := compare.CompareDirs(*compareOld, *compareNew, opts)
+	if err != nil {
+		glog.Fatalf("Error comparing %v to %v: %v", *compareOld, *compareNew, err)
+	}
+	for _, name := range skipped {
+		fmt.Fprintf(os.Stderr, "Skipping %v: not present in both directories\n", name)
+	}
+	names := make([]string, 0, len(diffs))
+	for name := range diffs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		diff := diffs[name]
+		if diff.Empty() {
+			continue
+		}
+		fmt.Printf("%v:\n", name)
+		for _, series := range diff.Added {
+			fmt.Printf("  + %v\n", series)
+		}
+		for _, series := range diff.Removed {
+			fmt.Printf("  - %v\n", series)
+		}
+		for _, series := range diff.Changed {
+			fmt.Printf("  ~ %v: %v -> %v\n", series, series.Old, series.New)
+		}
+	}
+}
+
+func main() {
+	flag.Parse()
+
+	if *compareOld != "" || *compareNew != "" {
+		if *compareOld == "" || *compareNew == "" {
+			glog.Fatalf("--compare-old and --compare-new must be set together")
+		}
+		runCompare()
+		return
+	}
+
+	selected := sets.NewString(strings.Split(*components, ",")...)
+
+	var grabber *metrics.MetricsGrabber
+	var err error
+	if *context != "" {
+		if *kubectlProxyAddress != "" {
+			glog.Fatalf("--context and --kubectl-proxy-address are mutually exclusive")
+		}
+		grabber, err = metrics.NewMetricsGrabberFromContext(*kubeconfig, *context, selected.Has("kubelets"), selected.Has("scheduler"), selected.Has("controller-manager"), selected.Has("apiserver"))
+	} else {
+		config, configErr := clientcmd.BuildConfigFromFlags(*master, *kubeconfig)
+		if configErr != nil {
+			glog.Fatalf("Error building client config: %v", configErr)
+		}
+		c, clientErr := client.New(config)
+		if clientErr != nil {
+			glog.Fatalf("Error creating client: %v", clientErr)
+		}
+		if *kubectlProxyAddress != "" {
+			grabber, err = metrics.NewLocalProxyMetricsGrabber(c, *kubectlProxyAddress, selected.Has("kubelets"), selected.Has("scheduler"), selected.Has("controller-manager"), selected.Has("apiserver"))
+		} else {
+			grabber, err = metrics.NewMetricsGrabber(c, selected.Has("kubelets"), selected.Has("scheduler"), selected.Has("controller-manager"), selected.Has("apiserver"))
+		}
+	}
+	if err != nil {
+		glog.Fatalf("Error creating MetricsGrabber: %v", err)
+	}
+	grabber.SetParallelism(*parallelism)
+
+	failed := false
+	if selected.Has("apiserver") {
+		if response, err := grabber.GrabFromApiServer(nil); err != nil {
+			fmt.Fprintf(os.Stderr, "Could not grab apiserver metrics: %v\n", err)
+			failed = true
+		} else if err := writeSnapshot("apiserver", metrics.Metrics(response)); err != nil {
+			fmt.Fprintf(os.Stderr, "Could not write apiserver metrics: %v\n", err)
+			failed = true
+		}
+	}
+	if selected.Has("scheduler") {
+		if response, err := grabber.GrabFromScheduler(nil); err != nil {
+			fmt.Fprintf(os.Stderr, "Could not grab scheduler metrics: %v\n", err)
+			failed = true
+		} else if err := writeSnapshot("scheduler", metrics.Metrics(response)); err != nil {
+			fmt.Fprintf(os.Stderr, "Could not write scheduler metrics: %v\n", err)
+			failed = true
+		}
+	}
+	if selected.Has("controller-manager") {
+		if response, err := grabber.GrabFromControllerManager(nil); err != nil {
+			fmt.Fprintf(os.Stderr, "Could not grab controller-manager metrics: %v\n", err)
+			failed = true
+		} else if err := writeSnapshot("controller-manager", metrics.Metrics(response)); err != nil {
+			fmt.Fprintf(os.Stderr, "Could not write controller-manager metrics: %v\n", err)
+			failed = true
+		}
+	}
+	if selected.Has("kubelets") {
+		perNode, err := grabber.GrabFromAllKubelets(nil)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Could not grab metrics from all kubelets: %v\n", err)
+			failed = true
+		}
+		for node, response := range perNode {
+			if err := writeSnapshot("kubelet-"+node, metrics.Metrics(response)); err != nil {
+				fmt.Fprintf(os.Stderr, "Could not write kubelet metrics for node %v: %v\n", node, err)
+				failed = true
+			}
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
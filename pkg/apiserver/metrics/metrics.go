@@ -68,6 +68,12 @@ func Monitor(verb, resource *string, client string, httpCode *int, reqStart time
 	requestLatenciesSummary.WithLabelValues(*verb, *resource).Observe(float64((time.Since(reqStart)) / time.Microsecond))
 }
 
+// Reset clears the apiserver's request count/latency metrics, so a
+// long-running suite can measure one phase of a test in isolation instead
+// of the cumulative totals since the apiserver started. It is registered
+// at the "/resetMetrics" path (see pkg/apiserver.New) and accepts any HTTP
+// method; there's no dedicated client verb for it, so callers just issue a
+// plain request against the path.
 func Reset(w http.ResponseWriter, req *http.Request) {
 	requestCounter.Reset()
 	requestLatencies.Reset()
@@ -98,7 +98,7 @@ func NewDeploymentController(client client.Interface, resyncPeriod controller.Re
 		client:          client,
 		expClient:       client.Extensions(),
 		eventRecorder:   eventBroadcaster.NewRecorder(api.EventSource{Component: "deployment-controller"}),
-		queue:           workqueue.New(),
+		queue:           workqueue.NewNamed("deployment"),
 		podExpectations: controller.NewControllerExpectations(),
 		rcExpectations:  controller.NewControllerExpectations(),
 	}
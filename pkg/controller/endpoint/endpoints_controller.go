@@ -15,7 +15,8 @@ limitations under the License.
 */
 
 // CAUTION: If you update code in this file, you may need to also update code
-//          in contrib/mesos/pkg/service/endpoints_controller.go
+//
+//	in contrib/mesos/pkg/service/endpoints_controller.go
 package endpoint
 
 import (
@@ -55,7 +56,7 @@ var (
 func NewEndpointController(client *client.Client, resyncPeriod controller.ResyncPeriodFunc) *EndpointController {
 	e := &EndpointController{
 		client: client,
-		queue:  workqueue.New(),
+		queue:  workqueue.NewNamed("endpoint"),
 	}
 
 	e.serviceStore.Store, e.serviceController = framework.NewInformer(
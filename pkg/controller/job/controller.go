@@ -80,7 +80,7 @@ func NewJobController(kubeClient client.Interface, resyncPeriod controller.Resyn
 			Recorder:   eventBroadcaster.NewRecorder(api.EventSource{Component: "job-controller"}),
 		},
 		expectations: controller.NewControllerExpectations(),
-		queue:        workqueue.New(),
+		queue:        workqueue.NewNamed("job"),
 		recorder:     eventBroadcaster.NewRecorder(api.EventSource{Component: "job-controller"}),
 	}
 
@@ -101,7 +101,7 @@ func NewReplicationManager(kubeClient client.Interface, resyncPeriod controller.
 		},
 		burstReplicas: burstReplicas,
 		expectations:  controller.NewControllerExpectations(),
-		queue:         workqueue.New(),
+		queue:         workqueue.NewNamed("replicationmanager"),
 	}
 
 	rm.rcStore.Store, rm.rcController = framework.NewInformer(
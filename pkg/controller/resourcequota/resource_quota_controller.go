@@ -58,7 +58,7 @@ func NewResourceQuotaController(kubeClient client.Interface, resyncPeriod contro
 
 	rq := &ResourceQuotaController{
 		kubeClient:   kubeClient,
-		queue:        workqueue.New(),
+		queue:        workqueue.NewNamed("resourcequota"),
 		resyncPeriod: resyncPeriod,
 	}
 
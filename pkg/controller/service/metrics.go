@@ -0,0 +1,68 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const serviceControllerSubsystem = "service_controller"
+
+var (
+	cloudProviderAPILatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: serviceControllerSubsystem,
+			Name:      "cloudprovider_api_latency_microseconds",
+			Help:      "Latency of cloud provider load balancer API calls, by operation",
+			Buckets:   prometheus.ExponentialBuckets(1000, 2, 15),
+		},
+		[]string{"operation"},
+	)
+	cloudProviderAPIErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: serviceControllerSubsystem,
+			Name:      "cloudprovider_api_errors_total",
+			Help:      "Number of cloud provider load balancer API calls that returned an error, by operation",
+		},
+		[]string{"operation"},
+	)
+)
+
+var registerMetrics sync.Once
+
+// RegisterMetrics registers the service controller's cloud provider API
+// metrics. Callers must invoke it once before the metrics can be scraped.
+func RegisterMetrics() {
+	registerMetrics.Do(func() {
+		prometheus.MustRegister(cloudProviderAPILatency)
+		prometheus.MustRegister(cloudProviderAPIErrors)
+	})
+}
+
+// observeCloudProviderCall records the latency and, if err is non-nil, the
+// error count of a cloud provider load balancer API call, labeled by
+// operation. It lets callers distinguish a slow/unreliable cloud provider
+// from a bug in the controller itself.
+func observeCloudProviderCall(operation string, start time.Time, err error) {
+	cloudProviderAPILatency.WithLabelValues(operation).Observe(float64(time.Since(start).Nanoseconds() / int64(time.Microsecond)))
+	if err != nil {
+		cloudProviderAPIErrors.WithLabelValues(operation).Inc()
+	}
+}
@@ -80,6 +80,7 @@ type ServiceController struct {
 // New returns a new service controller to keep cloud provider service resources
 // (like load balancers) in sync with the registry.
 func New(cloud cloudprovider.Interface, kubeClient client.Interface, clusterName string) *ServiceController {
+	RegisterMetrics()
 	broadcaster := record.NewBroadcaster()
 	broadcaster.StartRecordingToSink(kubeClient.Events(""))
 	recorder := broadcaster.NewRecorder(api.EventSource{Component: "service-controller"})
@@ -256,7 +257,9 @@ func (s *ServiceController) processDelta(delta *cache.Delta) (error, bool) {
 		s.cache.set(namespacedName.String(), cachedService)
 	case cache.Deleted:
 		s.eventRecorder.Event(service, api.EventTypeNormal, "DeletingLoadBalancer", "Deleting load balancer")
+		start := time.Now()
 		err := s.balancer.EnsureLoadBalancerDeleted(s.loadBalancerName(service), s.zone.Region)
+		observeCloudProviderCall("EnsureLoadBalancerDeleted", start, err)
 		if err != nil {
 			message := "Error deleting load balancer (will retry): " + err.Error()
 			s.eventRecorder.Event(service, api.EventTypeWarning, "DeletingLoadBalancerFailed", message)
@@ -294,7 +297,9 @@ func (s *ServiceController) createLoadBalancerIfNeeded(namespacedName types.Name
 			// If we don't have any cached memory of the load balancer, we have to ask
 			// the cloud provider for what it knows about it.
 			// Technically EnsureLoadBalancerDeleted can cope, but we want to post meaningful events
+			start := time.Now()
 			_, exists, err := s.balancer.GetLoadBalancer(s.loadBalancerName(service), s.zone.Region)
+			observeCloudProviderCall("GetLoadBalancer", start, err)
 			if err != nil {
 				return fmt.Errorf("Error getting LB for service %s: %v", namespacedName, err), retryable
 			}
@@ -306,7 +311,10 @@ func (s *ServiceController) createLoadBalancerIfNeeded(namespacedName types.Name
 		if needDelete {
 			glog.Infof("Deleting existing load balancer for service %s that no longer needs a load balancer.", namespacedName)
 			s.eventRecorder.Event(service, api.EventTypeNormal, "DeletingLoadBalancer", "Deleting load balancer")
-			if err := s.balancer.EnsureLoadBalancerDeleted(s.loadBalancerName(service), s.zone.Region); err != nil {
+			start := time.Now()
+			err := s.balancer.EnsureLoadBalancerDeleted(s.loadBalancerName(service), s.zone.Region)
+			observeCloudProviderCall("EnsureLoadBalancerDeleted", start, err)
+			if err != nil {
 				return err, retryable
 			}
 			s.eventRecorder.Event(service, api.EventTypeNormal, "DeletedLoadBalancer", "Deleted load balancer")
@@ -381,8 +389,10 @@ func (s *ServiceController) createLoadBalancer(service *api.Service) error {
 	// - Only one protocol supported per service
 	// - Not all cloud providers support all protocols and the next step is expected to return
 	//   an error for unsupported protocols
+	start := time.Now()
 	status, err := s.balancer.EnsureLoadBalancer(name, s.zone.Region, net.ParseIP(service.Spec.LoadBalancerIP),
 		ports, hostsFromNodeList(&nodes), service.Spec.SessionAffinity)
+	observeCloudProviderCall("EnsureLoadBalancer", start, err)
 	if err != nil {
 		return err
 	} else {
@@ -691,15 +701,20 @@ func (s *ServiceController) lockedUpdateLoadBalancerHosts(service *api.Service,
 
 	// This operation doesn't normally take very long (and happens pretty often), so we only record the final event
 	name := cloudprovider.GetLoadBalancerName(service)
+	start := time.Now()
 	err := s.balancer.UpdateLoadBalancer(name, s.zone.Region, hosts)
+	observeCloudProviderCall("UpdateLoadBalancer", start, err)
 	if err == nil {
 		s.eventRecorder.Event(service, api.EventTypeNormal, "UpdatedLoadBalancer", "Updated load balancer with new hosts")
 		return nil
 	}
 
 	// It's only an actual error if the load balancer still exists.
-	if _, exists, err := s.balancer.GetLoadBalancer(name, s.zone.Region); err != nil {
-		glog.Errorf("External error while checking if load balancer %q exists: name, %v", name, err)
+	getStart := time.Now()
+	_, exists, getErr := s.balancer.GetLoadBalancer(name, s.zone.Region)
+	observeCloudProviderCall("GetLoadBalancer", getStart, getErr)
+	if getErr != nil {
+		glog.Errorf("External error while checking if load balancer %q exists: name, %v", name, getErr)
 	} else if !exists {
 		return nil
 	}
@@ -17,12 +17,22 @@ limitations under the License.
 package metrics
 
 import (
+	"strconv"
+	"time"
+
 	"k8s.io/kubernetes/pkg/util/sets"
 
 	"github.com/prometheus/common/model"
 )
 
 var KnownApiServerMetrics = map[string][]string{
+	"apiserver_dropped_requests_total":               {"verb"},
+	"watch_cache_size":                               {"resource"},
+	"watch_cache_hit_count":                          {"resource"},
+	"watch_cache_miss_count":                         {"resource"},
+	"watch_cache_init_duration_seconds":              {"resource", "quantile"},
+	"apiserver_panic_count":                          {},
+	"etcd_object_counts":                             {"resource"},
 	"apiserver_request_count":                        {"verb", "resource", "client", "code"},
 	"apiserver_request_latencies_bucket":             {"verb", "resource", "le"},
 	"apiserver_request_latencies_count":              {"verb", "resource"},
@@ -48,6 +58,14 @@ var KnownApiServerMetrics = map[string][]string{
 	"rest_client_request_status_codes":               {"code", "host", "method"},
 }
 
+// KnownApiServerMetricsByVersion resolves the known-metrics schema for a
+// specific apiserver release via ResolveKnownMetrics. It currently has only
+// a "default" entry; add a "major.minor" entry here once a release is found
+// to have actually added or removed apiserver metric families.
+var KnownApiServerMetricsByVersion = KnownMetricsByVersion{
+	"default": KnownApiServerMetrics,
+}
+
 type ApiServerMetrics Metrics
 
 func (m *ApiServerMetrics) Equal(o ApiServerMetrics) bool {
@@ -62,6 +80,123 @@ func NewApiServerMetrics() ApiServerMetrics {
 	return ApiServerMetrics(result)
 }
 
+// RequestCount returns the samples for the apiserver's total request counter,
+// so callers don't need to know the raw metric name to get at it.
+func (m ApiServerMetrics) RequestCount() model.Samples {
+	return m["apiserver_request_count"]
+}
+
+// PanicCount returns the samples for the apiserver's panic-recovery counter,
+// so callers don't need to know the raw metric name to get at it.
+func (m ApiServerMetrics) PanicCount() model.Samples {
+	return m["apiserver_panic_count"]
+}
+
+// DroppedRequestsCount returns the samples for the apiserver's
+// dropped/rejected request counter (e.g. from max-in-flight limiting), so
+// callers don't need to know the raw metric name to get at it.
+func (m ApiServerMetrics) DroppedRequestsCount() model.Samples {
+	return m["apiserver_dropped_requests_total"]
+}
+
+// WatchCacheSize returns the samples for the apiserver's per-resource watch
+// cache size gauge, so callers don't need to know the raw metric name to
+// get at it.
+func (m ApiServerMetrics) WatchCacheSize() model.Samples {
+	return m["watch_cache_size"]
+}
+
+// ObjectCounts returns the samples for etcd's per-resource object count
+// gauge, so callers don't need to know the raw metric name to get at it.
+func (m ApiServerMetrics) ObjectCounts() model.Samples {
+	return m["etcd_object_counts"]
+}
+
+// RequestErrorRate returns the fraction of apiserver_request_count samples,
+// weighted by their value, whose "code" label is a server error (5xx). It
+// flags apiserver instability that's distinct from the slow-but-successful
+// requests a latency threshold alone would catch.
+func RequestErrorRate(samples model.Samples) float64 {
+	var total, errors float64
+	for _, sample := range samples {
+		value := float64(sample.Value)
+		total += value
+		if code := string(sample.Metric["code"]); len(code) == 3 && code[0] == '5' {
+			errors += value
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return errors / total
+}
+
+// RequestCountExcludingUserAgent returns the apiserver_request_count
+// samples in samples whose "client" label -- which the apiserver populates
+// from the request's raw User-Agent header -- doesn't match userAgent, so a
+// caller attributing API-call volume to tests can subtract a metrics
+// grabber's own scrape traffic (given a distinct MetricsGrabberOptions.UserAgent)
+// instead of miscounting it as the test's own usage. Returns samples
+// unchanged if userAgent is empty.
+func RequestCountExcludingUserAgent(samples model.Samples, userAgent string) model.Samples {
+	if userAgent == "" {
+		return samples
+	}
+	result := make(model.Samples, 0, len(samples))
+	for _, sample := range samples {
+		if string(sample.Metric["client"]) == userAgent {
+			continue
+		}
+		result = append(result, sample)
+	}
+	return result
+}
+
+// APICallKey identifies the (verb, resource) pair a latency breakdown entry
+// belongs to.
+type APICallKey struct {
+	Verb     string
+	Resource string
+}
+
+// LatencyQuantiles holds the 50th, 90th and 99th percentile latency for a
+// single (verb, resource) pair.
+type LatencyQuantiles struct {
+	Perc50 time.Duration
+	Perc90 time.Duration
+	Perc99 time.Duration
+}
+
+// APIServerLatencyBreakdown groups a snapshot's apiserver_request_latencies_summary
+// samples by (verb, resource), so callers that need per-call latency quantiles
+// (SLO verification, teardown reports) don't each have to parse labels and
+// match quantile strings themselves.
+func APIServerLatencyBreakdown(snapshot ApiServerMetrics) map[APICallKey]LatencyQuantiles {
+	result := make(map[APICallKey]LatencyQuantiles)
+	for _, sample := range snapshot["apiserver_request_latencies_summary"] {
+		quantile, err := strconv.ParseFloat(string(sample.Metric[model.QuantileLabel]), 64)
+		if err != nil {
+			continue
+		}
+		key := APICallKey{
+			Verb:     string(sample.Metric["verb"]),
+			Resource: string(sample.Metric["resource"]),
+		}
+		latency := time.Duration(int64(sample.Value)) * time.Microsecond
+		quantiles := result[key]
+		switch quantile {
+		case 0.5:
+			quantiles.Perc50 = latency
+		case 0.9:
+			quantiles.Perc90 = latency
+		case 0.99:
+			quantiles.Perc99 = latency
+		}
+		result[key] = quantiles
+	}
+	return result
+}
+
 func parseApiServerMetrics(data string, unknownMetrics sets.String) (ApiServerMetrics, error) {
 	result := NewApiServerMetrics()
 	if err := parseMetrics(data, KnownApiServerMetrics, (*Metrics)(&result), unknownMetrics); err != nil {
@@ -70,8 +205,22 @@ func parseApiServerMetrics(data string, unknownMetrics sets.String) (ApiServerMe
 	return result, nil
 }
 
-func (g *MetricsGrabber) getMetricsFromApiServer() (string, error) {
-	rawOutput, err := g.client.Get().RequestURI("/metrics").Do().Raw()
+func (g *MetricsGrabber) getMetricsFromApiServer(timeout time.Duration) (string, error) {
+	req := g.withUserAgent(g.metricsClient.Get().RequestURI(g.apiServerMetricsPath()).Timeout(timeout))
+	rawOutput, err := req.Do().Raw()
+	if err != nil {
+		return "", err
+	}
+	return string(rawOutput), nil
+}
+
+// getMetricsFromApiServerWithAccept is like getMetricsFromApiServer, but
+// sets the Accept header on the scrape request to accept, so a caller can
+// request a specific exposition format (e.g. protobuf) from a handler that
+// negotiates on it.
+func (g *MetricsGrabber) getMetricsFromApiServerWithAccept(timeout time.Duration, accept string) (string, error) {
+	req := g.withUserAgent(g.metricsClient.Get().RequestURI(g.apiServerMetricsPath()).SetHeader("Accept", accept).Timeout(timeout))
+	rawOutput, err := req.Do().Raw()
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,265 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package compare diffs two pkg/metrics snapshots -- added, removed and
+// changed series -- so the upgrade e2e and humans comparing two CI runs can
+// share the same comparison code instead of each growing their own ad-hoc
+// diffing.
+package compare
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+
+	"k8s.io/kubernetes/pkg/metrics"
+
+	"github.com/prometheus/common/model"
+)
+
+// Series identifies a single labeled time series within a metric family.
+type Series struct {
+	Metric string       `json:"metric"`
+	Labels model.Metric `json:"labels"`
+}
+
+func (s Series) String() string {
+	return fmt.Sprintf("%s%s", s.Metric, metrics.PrintSample(&model.Sample{Metric: s.Labels}))
+}
+
+// ChangedSeries is a series present in both snapshots whose value moved by
+// more than the comparison's noise threshold.
+type ChangedSeries struct {
+	Series
+	Old float64 `json:"old"`
+	New float64 `json:"new"`
+}
+
+// Delta is New minus Old.
+func (c ChangedSeries) Delta() float64 {
+	return c.New - c.Old
+}
+
+// Diff is the result of comparing two snapshots: series only in the new
+// snapshot, series only in the old one, and series present in both whose
+// value changed by more than the noise threshold.
+type Diff struct {
+	Added   []Series        `json:"added,omitempty"`
+	Removed []Series        `json:"removed,omitempty"`
+	Changed []ChangedSeries `json:"changed,omitempty"`
+}
+
+// Empty reports whether the diff found no added, removed or changed series.
+func (d Diff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Options controls how two snapshots are compared.
+type Options struct {
+	// NoiseThreshold is the minimum absolute value delta for a series
+	// present in both snapshots to be reported as Changed. Zero reports
+	// any non-identical value.
+	NoiseThreshold float64
+	// IgnoreRuntimeMetrics drops the standard Go/process metric families
+	// (see metrics.WithoutRuntimeMetrics) from both snapshots before
+	// comparing, since they're expected to differ run to run.
+	IgnoreRuntimeMetrics bool
+	// Rewrite normalizes known metric/label renames before comparing, so
+	// an upgrade comparison between two component versions reports real
+	// changes instead of a rename showing up as one series Added and
+	// another Removed.
+	Rewrite RenameRules
+}
+
+// RenameRules maps old metric and label names to their current names, for
+// normalizing a snapshot taken from an older component version before
+// comparing it against a newer one.
+type RenameRules struct {
+	// Metrics maps an old metric family name to its current name.
+	Metrics map[string]string `json:"metrics,omitempty"`
+	// Labels maps an old label name to its current name, applied to every
+	// sample's label set regardless of which metric family it belongs to.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// Empty reports whether r has no renames configured, so callers can skip
+// rewriting a snapshot entirely when there's nothing to normalize.
+func (r RenameRules) Empty() bool {
+	return len(r.Metrics) == 0 && len(r.Labels) == 0
+}
+
+// Rewrite returns a copy of snapshot with r's metric and label renames
+// applied. Samples whose metric family is renamed are merged into the new
+// family's sample list; samples whose family isn't mentioned in r.Metrics
+// are passed through under their existing name. Renamed families that
+// collide with an existing family in snapshot -- e.g. the new version
+// already reports under the new name -- have both sets of samples kept, so
+// Compare can still tell the two apart by their now-identical label sets.
+func (r RenameRules) Rewrite(snapshot metrics.Metrics) metrics.Metrics {
+	if r.Empty() {
+		return snapshot
+	}
+	result := make(metrics.Metrics, len(snapshot))
+	for name, samples := range snapshot {
+		newName := name
+		if renamed, ok := r.Metrics[name]; ok {
+			newName = renamed
+		}
+		for _, sample := range samples {
+			copied := *sample
+			if len(r.Labels) > 0 {
+				relabeled := make(model.Metric, len(sample.Metric))
+				for label, value := range sample.Metric {
+					if renamed, ok := r.Labels[string(label)]; ok {
+						label = model.LabelName(renamed)
+					}
+					relabeled[label] = value
+				}
+				copied.Metric = relabeled
+			}
+			result[newName] = append(result[newName], &copied)
+		}
+	}
+	return result
+}
+
+type indexedSample struct {
+	series Series
+	value  float64
+}
+
+func index(snapshot metrics.Metrics, opts Options) map[model.Fingerprint]indexedSample {
+	if opts.IgnoreRuntimeMetrics {
+		snapshot = snapshot.WithoutRuntimeMetrics()
+	}
+	snapshot = opts.Rewrite.Rewrite(snapshot)
+	result := make(map[model.Fingerprint]indexedSample)
+	for name, samples := range snapshot {
+		for _, sample := range samples {
+			fp := model.LabelSet(sample.Metric).Fingerprint()
+			result[fp] = indexedSample{
+				series: Series{Metric: name, Labels: sample.Metric},
+				value:  float64(sample.Value),
+			}
+		}
+	}
+	return result
+}
+
+// Compare diffs old against updated, returning what was added, removed, and
+// changed by more than opts.NoiseThreshold.
+func Compare(old, updated metrics.Metrics, opts Options) Diff {
+	oldIndex := index(old, opts)
+	newIndex := index(updated, opts)
+
+	var diff Diff
+	for fp, sample := range newIndex {
+		oldSample, found := oldIndex[fp]
+		if !found {
+			diff.Added = append(diff.Added, sample.series)
+			continue
+		}
+		delta := sample.value - oldSample.value
+		if delta < 0 {
+			delta = -delta
+		}
+		if delta > opts.NoiseThreshold {
+			diff.Changed = append(diff.Changed, ChangedSeries{Series: sample.series, Old: oldSample.value, New: sample.value})
+		}
+	}
+	for fp, sample := range oldIndex {
+		if _, found := newIndex[fp]; !found {
+			diff.Removed = append(diff.Removed, sample.series)
+		}
+	}
+
+	sort.Slice(diff.Added, func(i, j int) bool { return diff.Added[i].String() < diff.Added[j].String() })
+	sort.Slice(diff.Removed, func(i, j int) bool { return diff.Removed[i].String() < diff.Removed[j].String() })
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].String() < diff.Changed[j].String() })
+	return diff
+}
+
+// LoadSnapshot reads a metrics.Metrics snapshot serialized as JSON by
+// cmd/metricsgrab (or metrics.Metrics' own json.Marshal output).
+func LoadSnapshot(path string) (metrics.Metrics, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := make(metrics.Metrics)
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("parsing snapshot %v: %v", path, err)
+	}
+	return snapshot, nil
+}
+
+// CompareFiles loads the snapshot at each path and compares them.
+func CompareFiles(oldPath, newPath string, opts Options) (Diff, error) {
+	old, err := LoadSnapshot(oldPath)
+	if err != nil {
+		return Diff{}, err
+	}
+	updated, err := LoadSnapshot(newPath)
+	if err != nil {
+		return Diff{}, err
+	}
+	return Compare(old, updated, opts), nil
+}
+
+// CompareDirs compares every *.json snapshot file present in both oldDir and
+// newDir, matched by filename, and returns a Diff per matched component
+// name. Files present in only one directory are skipped with a note in the
+// returned skipped list, rather than failing the whole comparison.
+func CompareDirs(oldDir, newDir string, opts Options) (diffs map[string]Diff, skipped []string, err error) {
+	oldFiles, err := filepath.Glob(filepath.Join(oldDir, "*.json"))
+	if err != nil {
+		return nil, nil, err
+	}
+	newFiles, err := filepath.Glob(filepath.Join(newDir, "*.json"))
+	if err != nil {
+		return nil, nil, err
+	}
+	names := make(map[string]bool)
+	for _, path := range oldFiles {
+		names[filepath.Base(path)] = true
+	}
+	for _, path := range newFiles {
+		names[filepath.Base(path)] = true
+	}
+
+	diffs = make(map[string]Diff)
+	for name := range names {
+		oldPath := filepath.Join(oldDir, name)
+		newPath := filepath.Join(newDir, name)
+		if _, err := ioutil.ReadFile(oldPath); err != nil {
+			skipped = append(skipped, name)
+			continue
+		}
+		if _, err := ioutil.ReadFile(newPath); err != nil {
+			skipped = append(skipped, name)
+			continue
+		}
+		diff, err := CompareFiles(oldPath, newPath, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		diffs[name] = diff
+	}
+	sort.Strings(skipped)
+	return diffs, skipped, nil
+}
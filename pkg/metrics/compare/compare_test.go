@@ -0,0 +1,150 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compare
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/metrics"
+
+	"github.com/prometheus/common/model"
+)
+
+func sample(labels model.Metric, value float64) *model.Sample {
+	return &model.Sample{Metric: labels, Value: model.SampleValue(value)}
+}
+
+func TestCompare(t *testing.T) {
+	old := metrics.Metrics{
+		"apiserver_request_count": {
+			sample(model.Metric{model.MetricNameLabel: "apiserver_request_count", "verb": "GET"}, 10),
+			sample(model.Metric{model.MetricNameLabel: "apiserver_request_count", "verb": "DELETE"}, 1),
+		},
+	}
+	updated := metrics.Metrics{
+		"apiserver_request_count": {
+			sample(model.Metric{model.MetricNameLabel: "apiserver_request_count", "verb": "GET"}, 50),
+			sample(model.Metric{model.MetricNameLabel: "apiserver_request_count", "verb": "POST"}, 3),
+		},
+	}
+
+	diff := Compare(old, updated, Options{NoiseThreshold: 5})
+	if len(diff.Added) != 1 || diff.Added[0].Labels["verb"] != "POST" {
+		t.Errorf("Added = %v, want just the POST series", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].Labels["verb"] != "DELETE" {
+		t.Errorf("Removed = %v, want just the DELETE series", diff.Removed)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].Labels["verb"] != "GET" {
+		t.Errorf("Changed = %v, want just the GET series", diff.Changed)
+	}
+	if diff.Empty() {
+		t.Error("Empty() = true for a diff with changes")
+	}
+}
+
+func TestCompareBelowNoiseThreshold(t *testing.T) {
+	old := metrics.Metrics{"m": {sample(model.Metric{model.MetricNameLabel: "m"}, 100)}}
+	updated := metrics.Metrics{"m": {sample(model.Metric{model.MetricNameLabel: "m"}, 101)}}
+
+	diff := Compare(old, updated, Options{NoiseThreshold: 5})
+	if !diff.Empty() {
+		t.Errorf("Compare() = %+v, want an empty diff (delta below noise threshold)", diff)
+	}
+}
+
+func TestRenameRulesRewrite(t *testing.T) {
+	rules := RenameRules{
+		Metrics: map[string]string{"old_metric_name": "new_metric_name"},
+		Labels:  map[string]string{"old_label": "new_label"},
+	}
+	snapshot := metrics.Metrics{
+		"old_metric_name":  {sample(model.Metric{"old_label": "x"}, 1)},
+		"untouched_metric": {sample(model.Metric{"old_label": "y"}, 2)},
+	}
+
+	rewritten := rules.Rewrite(snapshot)
+	if _, ok := rewritten["old_metric_name"]; ok {
+		t.Error("rewritten snapshot still has the old metric name as a key")
+	}
+	renamedSamples := rewritten["new_metric_name"]
+	if len(renamedSamples) != 1 || renamedSamples[0].Metric["new_label"] != "x" {
+		t.Errorf("new_metric_name samples = %v, want a single sample with new_label=x", renamedSamples)
+	}
+	untouched := rewritten["untouched_metric"]
+	if len(untouched) != 1 || untouched[0].Metric["new_label"] != "y" {
+		t.Errorf("untouched_metric samples = %v, want its label renamed too", untouched)
+	}
+}
+
+func TestRenameRulesEmpty(t *testing.T) {
+	snapshot := metrics.Metrics{"m": {sample(nil, 1)}}
+	if got := (RenameRules{}).Rewrite(snapshot); !got.Equal(snapshot) {
+		t.Errorf("Rewrite with empty rules = %v, want the snapshot unchanged", got)
+	}
+}
+
+func writeSnapshot(t *testing.T, dir, name string, m metrics.Metrics) {
+	t.Helper()
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("marshaling snapshot: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+		t.Fatalf("writing snapshot: %v", err)
+	}
+}
+
+func TestCompareDirsOneSidedFiles(t *testing.T) {
+	oldDir, err := ioutil.TempDir("", "compare-old")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer os.RemoveAll(oldDir)
+	newDir, err := ioutil.TempDir("", "compare-new")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer os.RemoveAll(newDir)
+
+	shared := metrics.Metrics{"m": {sample(model.Metric{model.MetricNameLabel: "m"}, 1)}}
+	writeSnapshot(t, oldDir, "apiserver.json", shared)
+	writeSnapshot(t, newDir, "apiserver.json", shared)
+	writeSnapshot(t, oldDir, "only-old.json", shared)
+	writeSnapshot(t, newDir, "only-new.json", shared)
+
+	diffs, skipped, err := CompareDirs(oldDir, newDir, Options{})
+	if err != nil {
+		t.Fatalf("CompareDirs: %v", err)
+	}
+	if _, ok := diffs["apiserver.json"]; !ok {
+		t.Errorf("diffs = %v, want an entry for apiserver.json", diffs)
+	}
+	wantSkipped := []string{"only-new.json", "only-old.json"}
+	if len(skipped) != len(wantSkipped) {
+		t.Fatalf("skipped = %v, want %v", skipped, wantSkipped)
+	}
+	for i, name := range wantSkipped {
+		if skipped[i] != name {
+			t.Errorf("skipped[%d] = %v, want %v", i, skipped[i], name)
+		}
+	}
+}
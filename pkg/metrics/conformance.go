@@ -0,0 +1,122 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+)
+
+// protobufAcceptHeader asks a client_golang-instrumented handler
+// (expfmt.Negotiate) to serve its protobuf exposition format instead of
+// the default text format. The text/plain fallback keeps the scrape from
+// failing outright against a handler that doesn't negotiate at all.
+const protobufAcceptHeader = string(expfmt.FmtProtoDelim) + ",text/plain;q=0.5"
+
+// decodeExposition parses a raw scrape body in the given exposition format
+// into a Metrics snapshot, keeping every family it finds rather than
+// filtering to a known-metrics whitelist -- CheckTextProtobufConformance
+// only cares whether the two encodings agree with each other, not whether
+// either is a family pkg/metrics already knows about.
+func decodeExposition(data string, format expfmt.Format) (Metrics, error) {
+	dec, err := expfmt.NewDecoder(strings.NewReader(data), format)
+	if err != nil {
+		return nil, err
+	}
+	decoder := expfmt.SampleDecoder{Dec: dec, Opts: &expfmt.DecodeOptions{}}
+	result := make(Metrics)
+	for {
+		var v model.Vector
+		if err := decoder.Decode(&v); err != nil {
+			if err == io.EOF {
+				return result, nil
+			}
+			return nil, err
+		}
+		for _, metric := range v {
+			name := string(metric.Metric[model.MetricNameLabel])
+			result[name] = append(result[name], metric)
+		}
+	}
+}
+
+// CheckTextProtobufConformance scrapes podName's metrics endpoint once in
+// the text exposition format and once in the protobuf format, and returns
+// an error naming any family present in only one of the two. The two
+// scrapes aren't atomic, so differing sample values are expected and
+// ignored; what this guards against is a handler whose protobuf encoding
+// path serves a stale or filtered registry that's silently drifted from
+// the text path everything else exercises.
+func (g *MetricsGrabber) CheckTextProtobufConformance(podName, namespace string, port int, timeout time.Duration) error {
+	text, err := g.getMetricsFromPodWithAccept(podName, namespace, port, timeout, string(expfmt.FmtText))
+	if err != nil {
+		return fmt.Errorf("scraping text exposition: %v", err)
+	}
+	proto, err := g.getMetricsFromPodWithAccept(podName, namespace, port, timeout, protobufAcceptHeader)
+	if err != nil {
+		return fmt.Errorf("scraping protobuf exposition: %v", err)
+	}
+
+	textMetrics, err := decodeExposition(text, expfmt.FmtText)
+	if err != nil {
+		return fmt.Errorf("decoding text exposition: %v", err)
+	}
+	protoMetrics, err := decodeExposition(proto, expfmt.FmtProtoDelim)
+	if err != nil {
+		return fmt.Errorf("decoding protobuf exposition: %v", err)
+	}
+
+	onlyText, onlyProto := CompareFamilySets(textMetrics, protoMetrics)
+	if len(onlyText) > 0 || len(onlyProto) > 0 {
+		return fmt.Errorf("text and protobuf expositions disagree on families: only in text %v, only in protobuf %v", onlyText, onlyProto)
+	}
+	return nil
+}
+
+// CheckApiServerTextProtobufConformance is CheckTextProtobufConformance for
+// the apiserver, which is scraped directly rather than through a pod
+// proxy.
+func (g *MetricsGrabber) CheckApiServerTextProtobufConformance(timeout time.Duration) error {
+	text, err := g.getMetricsFromApiServerWithAccept(timeout, string(expfmt.FmtText))
+	if err != nil {
+		return fmt.Errorf("scraping text exposition: %v", err)
+	}
+	proto, err := g.getMetricsFromApiServerWithAccept(timeout, protobufAcceptHeader)
+	if err != nil {
+		return fmt.Errorf("scraping protobuf exposition: %v", err)
+	}
+
+	textMetrics, err := decodeExposition(text, expfmt.FmtText)
+	if err != nil {
+		return fmt.Errorf("decoding text exposition: %v", err)
+	}
+	protoMetrics, err := decodeExposition(proto, expfmt.FmtProtoDelim)
+	if err != nil {
+		return fmt.Errorf("decoding protobuf exposition: %v", err)
+	}
+
+	onlyText, onlyProto := CompareFamilySets(textMetrics, protoMetrics)
+	if len(onlyText) > 0 || len(onlyProto) > 0 {
+		return fmt.Errorf("text and protobuf expositions disagree on families: only in text %v, only in protobuf %v", onlyText, onlyProto)
+	}
+	return nil
+}
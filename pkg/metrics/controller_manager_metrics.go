@@ -23,21 +23,48 @@ import (
 )
 
 var KnownControllerManagerMetrics = map[string][]string{
-	"etcd_helper_cache_entry_count":                  {},
-	"etcd_helper_cache_hit_count":                    {},
-	"etcd_helper_cache_miss_count":                   {},
-	"etcd_request_cache_add_latencies_summary":       {"quantile"},
-	"etcd_request_cache_add_latencies_summary_count": {},
-	"etcd_request_cache_add_latencies_summary_sum":   {},
-	"etcd_request_cache_get_latencies_summary":       {"quantile"},
-	"etcd_request_cache_get_latencies_summary_count": {},
-	"etcd_request_cache_get_latencies_summary_sum":   {},
-	"get_token_count":                                {},
-	"get_token_fail_count":                           {},
-	"rest_client_request_latency_microseconds":       {"url", "verb", "quantile"},
-	"rest_client_request_latency_microseconds_count": {"url", "verb"},
-	"rest_client_request_latency_microseconds_sum":   {"url", "verb"},
-	"rest_client_request_status_codes":               {"method", "code", "host"},
+	"etcd_helper_cache_entry_count":                                       {},
+	"etcd_helper_cache_hit_count":                                         {},
+	"etcd_helper_cache_miss_count":                                        {},
+	"etcd_request_cache_add_latencies_summary":                            {"quantile"},
+	"etcd_request_cache_add_latencies_summary_count":                      {},
+	"etcd_request_cache_add_latencies_summary_sum":                        {},
+	"etcd_request_cache_get_latencies_summary":                            {"quantile"},
+	"etcd_request_cache_get_latencies_summary_count":                      {},
+	"etcd_request_cache_get_latencies_summary_sum":                        {},
+	"gc_controller_deleted_pods_total":                                    {},
+	"gc_controller_delete_pod_errors_total":                               {},
+	"get_token_count":                                                     {},
+	"get_token_fail_count":                                                {},
+	"horizontal_pod_autoscaler_reconcile_count":                           {},
+	"horizontal_pod_autoscaler_metric_fetch_errors_total":                 {},
+	"attachdetach_controller_volume_operation_latency_microseconds":       {"operation_name", "plugin_name", "quantile"},
+	"attachdetach_controller_volume_operation_latency_microseconds_count": {"operation_name", "plugin_name"},
+	"attachdetach_controller_volume_operation_latency_microseconds_sum":   {"operation_name", "plugin_name"},
+	"workqueue_depth":                                                     {"name"},
+	"workqueue_adds":                                                      {"name"},
+	"workqueue_queue_latency_microseconds":                                {"name", "quantile"},
+	"workqueue_work_duration_microseconds":                                {"name", "quantile"},
+	"namespace_controller_deletion_latency_microseconds":                  {"quantile"},
+	"namespace_controller_deletion_latency_microseconds_count":            {},
+	"namespace_controller_deletion_latency_microseconds_sum":              {},
+	"rest_client_request_latency_microseconds":                            {"url", "verb", "quantile"},
+	"rest_client_request_latency_microseconds_count":                      {"url", "verb"},
+	"rest_client_request_latency_microseconds_sum":                        {"url", "verb"},
+	"rest_client_request_status_codes":                                    {"method", "code", "host"},
+	"service_controller_cloudprovider_api_latency_microseconds":           {"operation", "quantile"},
+	"service_controller_cloudprovider_api_latency_microseconds_count":     {"operation"},
+	"service_controller_cloudprovider_api_latency_microseconds_sum":       {"operation"},
+	"service_controller_cloudprovider_api_errors_total":                   {"operation"},
+}
+
+// KnownControllerManagerMetricsByVersion resolves the known-metrics schema
+// for a specific controller-manager release via ResolveKnownMetrics. It
+// currently has only a "default" entry; add a "major.minor" entry here once
+// a release is found to have actually added or removed controller-manager
+// metric families.
+var KnownControllerManagerMetricsByVersion = KnownMetricsByVersion{
+	"default": KnownControllerManagerMetrics,
 }
 
 type ControllerManagerMetrics Metrics
@@ -54,6 +81,89 @@ func NewControllerManagerMetrics() ControllerManagerMetrics {
 	return ControllerManagerMetrics(result)
 }
 
+// DeletionLatencyCount returns the samples for the namespace controller's
+// deletion latency sample count, so callers don't need to know the raw
+// metric name to get at it.
+func (m ControllerManagerMetrics) DeletionLatencyCount() model.Samples {
+	return m["namespace_controller_deletion_latency_microseconds_count"]
+}
+
+// DeletionLatencySum returns the samples for the namespace controller's
+// cumulative deletion latency, so callers don't need to know the raw metric
+// name to get at it.
+func (m ControllerManagerMetrics) DeletionLatencySum() model.Samples {
+	return m["namespace_controller_deletion_latency_microseconds_sum"]
+}
+
+// DeletedPodsCount returns the samples for the terminated-pod GC
+// controller's deletion counter, so callers don't need to know the raw
+// metric name to get at it.
+func (m ControllerManagerMetrics) DeletedPodsCount() model.Samples {
+	return m["gc_controller_deleted_pods_total"]
+}
+
+// DeletePodErrorsCount returns the samples for the terminated-pod GC
+// controller's deletion error counter, so callers don't need to know the
+// raw metric name to get at it.
+func (m ControllerManagerMetrics) DeletePodErrorsCount() model.Samples {
+	return m["gc_controller_delete_pod_errors_total"]
+}
+
+// HPAReconcileCount returns the samples for the horizontal pod autoscaler
+// control loop's reconcile counter, so callers don't need to know the raw
+// metric name to get at it.
+func (m ControllerManagerMetrics) HPAReconcileCount() model.Samples {
+	return m["horizontal_pod_autoscaler_reconcile_count"]
+}
+
+// HPAMetricFetchErrorsCount returns the samples for the horizontal pod
+// autoscaler control loop's metric-fetch error counter, so callers don't
+// need to know the raw metric name to get at it.
+func (m ControllerManagerMetrics) HPAMetricFetchErrorsCount() model.Samples {
+	return m["horizontal_pod_autoscaler_metric_fetch_errors_total"]
+}
+
+// VolumeOperationLatencyCount returns the samples for the attach/detach
+// controller's per-plugin volume operation latency sample count, so callers
+// don't need to know the raw metric name to get at it.
+func (m ControllerManagerMetrics) VolumeOperationLatencyCount() model.Samples {
+	return m["attachdetach_controller_volume_operation_latency_microseconds_count"]
+}
+
+// VolumeOperationLatencySum returns the samples for the attach/detach
+// controller's cumulative per-plugin volume operation latency, so callers
+// don't need to know the raw metric name to get at it.
+func (m ControllerManagerMetrics) VolumeOperationLatencySum() model.Samples {
+	return m["attachdetach_controller_volume_operation_latency_microseconds_sum"]
+}
+
+// WorkqueueDepth returns the samples for controller workqueue depth gauges,
+// so callers don't need to know the raw metric name to get at it.
+func (m ControllerManagerMetrics) WorkqueueDepth() model.Samples {
+	return m["workqueue_depth"]
+}
+
+// CloudProviderAPILatencyCount returns the samples for the service
+// controller's per-operation cloud provider API call latency sample count,
+// so callers don't need to know the raw metric name to get at it.
+func (m ControllerManagerMetrics) CloudProviderAPILatencyCount() model.Samples {
+	return m["service_controller_cloudprovider_api_latency_microseconds_count"]
+}
+
+// CloudProviderAPILatencySum returns the samples for the service
+// controller's cumulative per-operation cloud provider API call latency, so
+// callers don't need to know the raw metric name to get at it.
+func (m ControllerManagerMetrics) CloudProviderAPILatencySum() model.Samples {
+	return m["service_controller_cloudprovider_api_latency_microseconds_sum"]
+}
+
+// CloudProviderAPIErrorsCount returns the samples for the service
+// controller's per-operation cloud provider API error counter, so callers
+// don't need to know the raw metric name to get at it.
+func (m ControllerManagerMetrics) CloudProviderAPIErrorsCount() model.Samples {
+	return m["service_controller_cloudprovider_api_errors_total"]
+}
+
 func parseControllerManagerMetrics(data string, unknownMetrics sets.String) (ControllerManagerMetrics, error) {
 	result := NewControllerManagerMetrics()
 	if err := parseMetrics(data, KnownControllerManagerMetrics, (*Metrics)(&result), unknownMetrics); err != nil {
@@ -0,0 +1,71 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/util/sets"
+
+	"github.com/prometheus/common/model"
+)
+
+// dnsmasqMetricsPort is the port the dnsmasq-metrics sidecar in the kube-dns
+// pod exposes its Prometheus endpoint on.
+const dnsmasqMetricsPort = 10054
+
+// KnownDnsmasqMetrics covers the dnsmasq cache counters exported by the
+// dnsmasq-metrics sidecar, so the DNS autoscaling e2e can make assertions
+// about cache effectiveness instead of only resolution success.
+var KnownDnsmasqMetrics = map[string][]string{
+	"dnsmasq_cache_hits_total":       {},
+	"dnsmasq_cache_misses_total":     {},
+	"dnsmasq_cache_evictions_total":  {},
+	"dnsmasq_cache_insertions_total": {},
+	"dnsmasq_cache_size":             {},
+}
+
+type DnsmasqMetrics Metrics
+
+func (m *DnsmasqMetrics) Equal(o DnsmasqMetrics) bool {
+	return (*Metrics)(m).Equal(Metrics(o))
+}
+
+func NewDnsmasqMetrics() DnsmasqMetrics {
+	result := NewMetrics()
+	for metric := range KnownDnsmasqMetrics {
+		result[metric] = make(model.Samples, 0)
+	}
+	return DnsmasqMetrics(result)
+}
+
+func parseDnsmasqMetrics(data string, unknownMetrics sets.String) (DnsmasqMetrics, error) {
+	result := NewDnsmasqMetrics()
+	if err := parseMetrics(data, KnownDnsmasqMetrics, (*Metrics)(&result), unknownMetrics); err != nil {
+		return DnsmasqMetrics{}, err
+	}
+	return result, nil
+}
+
+// GrabFromDnsmasq scrapes dnsmasq cache metrics from the dnsmasq-metrics
+// sidecar in the given kube-dns pod.
+func (g *MetricsGrabber) GrabFromDnsmasq(podName string, unknownMetrics sets.String) (DnsmasqMetrics, error) {
+	output, err := g.getMetricsFromPod(podName, api.NamespaceSystem, dnsmasqMetricsPort, g.options.DefaultTimeout)
+	if err != nil {
+		return DnsmasqMetrics{}, err
+	}
+	return parseDnsmasqMetrics(output, unknownMetrics)
+}
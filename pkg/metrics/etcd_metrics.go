@@ -0,0 +1,72 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"k8s.io/kubernetes/pkg/util/sets"
+
+	"github.com/prometheus/common/model"
+)
+
+// EtcdClientPort is etcd's client port on a GCE-style master, as set by
+// cluster/saltbase/salt/etcd/etcd.manifest. Unlike the kubernetes
+// components in pkg/master/ports, etcd's listen port isn't something this
+// codebase controls, so it's kept local to this file rather than in that
+// shared registry.
+const EtcdClientPort = 4001
+
+// KnownEtcdMetrics lists the metric families this suite has actually
+// observed from etcd's own /metrics endpoint. It's deliberately small --
+// only the handful of generic process and store metrics every etcd version
+// in this repo's supported range exposes -- rather than the full exposition,
+// which has changed release to release; extend it as real etcd runs turn up
+// more that's worth asserting on.
+var KnownEtcdMetrics = map[string][]string{
+	"process_start_time_seconds":        {},
+	"process_resident_memory_bytes":     {},
+	"process_cpu_seconds_total":         {},
+	"etcd_debugging_store_reads_total":  {"action"},
+	"etcd_debugging_store_writes_total": {"action"},
+}
+
+type EtcdMetrics Metrics
+
+func (m *EtcdMetrics) Equal(o EtcdMetrics) bool {
+	return (*Metrics)(m).Equal(Metrics(o))
+}
+
+func NewEtcdMetrics() EtcdMetrics {
+	result := NewMetrics()
+	for metric := range KnownEtcdMetrics {
+		result[metric] = make(model.Samples, 0)
+	}
+	return EtcdMetrics(result)
+}
+
+// ResidentMemory returns the samples for etcd's own process resident memory
+// gauge, so callers don't need to know the raw metric name to get at it.
+func (m EtcdMetrics) ResidentMemory() model.Samples {
+	return m["process_resident_memory_bytes"]
+}
+
+func parseEtcdMetrics(data string, unknownMetrics sets.String) (EtcdMetrics, error) {
+	result := NewEtcdMetrics()
+	if err := parseMetrics(data, KnownEtcdMetrics, (*Metrics)(&result), unknownMetrics); err != nil {
+		return EtcdMetrics{}, err
+	}
+	return result, nil
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+
+	"github.com/prometheus/common/model"
+)
+
+// MetricType documents a metric family's Prometheus type, so an
+// ExpectedMetric's value assertion knows whether to sum a family's samples
+// (Counter, or a Histogram/Summary's _count) or take the largest one (Gauge).
+type MetricType int
+
+const (
+	Counter MetricType = iota
+	Gauge
+	Histogram
+	Summary
+)
+
+func (t MetricType) String() string {
+	switch t {
+	case Counter:
+		return "counter"
+	case Gauge:
+		return "gauge"
+	case Histogram:
+		return "histogram"
+	case Summary:
+		return "summary"
+	default:
+		return "unknown"
+	}
+}
+
+// ExpectedMetric is one metric family's validation spec, built fluently via
+// Expect(). Check runs it directly against a Metrics snapshot, and
+// checkMetrics (test/e2e/metrics_grabber_test.go) accepts a list of these
+// alongside a component's usual known-metrics schema -- so an e2e suite
+// outside the metrics-grabber tests can assert on the one or two metrics it
+// specifically cares about (e.g. "this addon's request counter went up")
+// without hand-building a label-set map just for that.
+type ExpectedMetric struct {
+	family      string
+	metricType  MetricType
+	labels      []string
+	minValue    float64
+	hasMinValue bool
+}
+
+// Expect starts a new ExpectedMetric builder.
+func Expect() *ExpectedMetric {
+	return &ExpectedMetric{}
+}
+
+// Family sets the metric family name to validate, e.g. "apiserver_request_count".
+func (e *ExpectedMetric) Family(name string) *ExpectedMetric {
+	e.family = name
+	return e
+}
+
+// Type records the family's Prometheus type. It's informational except for
+// ValueAtLeast, which uses it to decide whether to sum the family's samples
+// or take the largest one.
+func (e *ExpectedMetric) Type(t MetricType) *ExpectedMetric {
+	e.metricType = t
+	return e
+}
+
+// Labels sets the labels every sample in this family is expected to carry.
+func (e *ExpectedMetric) Labels(labels ...string) *ExpectedMetric {
+	e.labels = labels
+	return e
+}
+
+// ValueAtLeast requires the family's value to be at least min: the samples
+// summed together for a Counter, Histogram or Summary, or the largest single
+// sample for a Gauge.
+func (e *ExpectedMetric) ValueAtLeast(min float64) *ExpectedMetric {
+	e.minValue = min
+	e.hasMinValue = true
+	return e
+}
+
+// FamilyName returns the family name this spec validates.
+func (e *ExpectedMetric) FamilyName() string {
+	return e.family
+}
+
+// Check validates data against e, returning a human-readable description of
+// every way it fell short (nil if data satisfies the spec).
+func (e *ExpectedMetric) Check(data Metrics) []string {
+	samples, ok := data[e.family]
+	if !ok || len(samples) == 0 {
+		return []string{fmt.Sprintf("%v (%v): expected metric not present", e.family, e.metricType)}
+	}
+
+	var problems []string
+	for _, label := range e.labels {
+		found := false
+		for _, sample := range samples {
+			if _, ok := sample.Metric[model.LabelName(label)]; ok {
+				found = true
+				break
+			}
+		}
+		if !found {
+			problems = append(problems, fmt.Sprintf("%v: expected label %q not present on any sample", e.family, label))
+		}
+	}
+
+	if e.hasMinValue {
+		value := SumValues(samples)
+		if e.metricType == Gauge {
+			value, _ = latestValue(samples)
+		}
+		if value < e.minValue {
+			problems = append(problems, fmt.Sprintf("%v: value %v below expected minimum %v", e.family, value, e.minValue))
+		}
+	}
+	return problems
+}
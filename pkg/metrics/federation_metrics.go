@@ -0,0 +1,142 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/labels"
+	"k8s.io/kubernetes/pkg/util/sets"
+
+	"github.com/prometheus/common/model"
+)
+
+// NamespaceFederation is the namespace the federation control plane
+// (federation-apiserver, federation-controller-manager) is deployed into.
+const NamespaceFederation = "federation"
+
+var KnownFederationApiServerMetrics = map[string][]string{
+	"apiserver_request_count":                        {"verb", "resource", "client", "code"},
+	"apiserver_request_latencies_bucket":             {"verb", "resource", "le"},
+	"apiserver_request_latencies_count":              {"verb", "resource"},
+	"apiserver_request_latencies_sum":                {"verb", "resource"},
+	"etcd_helper_cache_entry_count":                  {},
+	"etcd_helper_cache_hit_count":                    {},
+	"etcd_helper_cache_miss_count":                   {},
+	"etcd_request_latencies_summary":                 {"operation", "type", "quantile"},
+	"etcd_request_latencies_summary_count":           {"operation", "type"},
+	"etcd_request_latencies_summary_sum":             {"operation", "type"},
+	"rest_client_request_latency_microseconds":       {"url", "verb", "quantile"},
+	"rest_client_request_latency_microseconds_count": {"url", "verb"},
+	"rest_client_request_latency_microseconds_sum":   {"url", "verb"},
+}
+
+var KnownFederationControllerManagerMetrics = map[string][]string{
+	"rest_client_request_latency_microseconds":       {"url", "verb", "quantile"},
+	"rest_client_request_latency_microseconds_count": {"url", "verb"},
+	"rest_client_request_latency_microseconds_sum":   {"url", "verb"},
+	"rest_client_request_status_codes":               {"method", "code", "host"},
+}
+
+type FederationApiServerMetrics Metrics
+
+func (m *FederationApiServerMetrics) Equal(o FederationApiServerMetrics) bool {
+	return (*Metrics)(m).Equal(Metrics(o))
+}
+
+func NewFederationApiServerMetrics() FederationApiServerMetrics {
+	result := NewMetrics()
+	for metric := range KnownFederationApiServerMetrics {
+		result[metric] = make(model.Samples, 0)
+	}
+	return FederationApiServerMetrics(result)
+}
+
+func parseFederationApiServerMetrics(data string, unknownMetrics sets.String) (FederationApiServerMetrics, error) {
+	result := NewFederationApiServerMetrics()
+	if err := parseMetrics(data, KnownFederationApiServerMetrics, (*Metrics)(&result), unknownMetrics); err != nil {
+		return FederationApiServerMetrics{}, err
+	}
+	return result, nil
+}
+
+type FederationControllerManagerMetrics Metrics
+
+func (m *FederationControllerManagerMetrics) Equal(o FederationControllerManagerMetrics) bool {
+	return (*Metrics)(m).Equal(Metrics(o))
+}
+
+func NewFederationControllerManagerMetrics() FederationControllerManagerMetrics {
+	result := NewMetrics()
+	for metric := range KnownFederationControllerManagerMetrics {
+		result[metric] = make(model.Samples, 0)
+	}
+	return FederationControllerManagerMetrics(result)
+}
+
+func parseFederationControllerManagerMetrics(data string, unknownMetrics sets.String) (FederationControllerManagerMetrics, error) {
+	result := NewFederationControllerManagerMetrics()
+	if err := parseMetrics(data, KnownFederationControllerManagerMetrics, (*Metrics)(&result), unknownMetrics); err != nil {
+		return FederationControllerManagerMetrics{}, err
+	}
+	return result, nil
+}
+
+// findFederationPod locates the pod for a federation control-plane
+// component in NamespaceFederation by its "app" or "component" label.
+func (g *MetricsGrabber) findFederationPod(component string) (*api.Pod, error) {
+	pods, err := g.client.Pods(NamespaceFederation).List(api.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{"app": component}),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(pods.Items) == 0 {
+		return nil, fmt.Errorf("no %q pods found in namespace %q", component, NamespaceFederation)
+	}
+	return &pods.Items[0], nil
+}
+
+// GrabFromFederationApiServer scrapes metrics from the federation-apiserver,
+// discovered by its pod in NamespaceFederation.
+func (g *MetricsGrabber) GrabFromFederationApiServer(unknownMetrics sets.String) (FederationApiServerMetrics, error) {
+	pod, err := g.findFederationPod("federation-apiserver")
+	if err != nil {
+		return FederationApiServerMetrics{}, err
+	}
+	output, err := g.getMetricsFromPod(pod.Name, NamespaceFederation, 8080, g.options.DefaultTimeout)
+	if err != nil {
+		return FederationApiServerMetrics{}, err
+	}
+	return parseFederationApiServerMetrics(output, unknownMetrics)
+}
+
+// GrabFromFederationControllerManager scrapes metrics from the
+// federation-controller-manager, discovered by its pod in
+// NamespaceFederation.
+func (g *MetricsGrabber) GrabFromFederationControllerManager(unknownMetrics sets.String) (FederationControllerManagerMetrics, error) {
+	pod, err := g.findFederationPod("federation-controller-manager")
+	if err != nil {
+		return FederationControllerManagerMetrics{}, err
+	}
+	output, err := g.getMetricsFromPod(pod.Name, NamespaceFederation, 8080, g.options.DefaultTimeout)
+	if err != nil {
+		return FederationControllerManagerMetrics{}, err
+	}
+	return parseFederationControllerManagerMetrics(output, unknownMetrics)
+}
@@ -0,0 +1,44 @@
+// +build gofuzz
+
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import "k8s.io/kubernetes/pkg/util/sets"
+
+// Fuzz parseMetrics, the shared text-exposition decoder every component's
+// parseXMetrics in this package runs its scrape through, with
+// github.com/dvyukov/go-fuzz:
+//
+//     go-fuzz-build k8s.io/kubernetes/pkg/metrics
+//     go-fuzz -bin metrics-fuzz.zip -workdir fuzz
+//
+// parseMetrics ingests whatever text a component's /metrics handler returns,
+// so malformed exposition, escape sequences, NaN/Inf values and oversized
+// lines are all fair game here -- this scrapes output from arbitrary addons,
+// not just components we control, so a crash on adversarial input shouldn't
+// be able to take a MetricsGrabber run down with it.
+//
+// Further input samples should go in the folder fuzz/corpus.
+func Fuzz(in []byte) int {
+	output := Metrics{}
+	unknownMetrics := sets.NewString()
+	if err := parseMetrics(string(in), nil, &output, unknownMetrics); err != nil {
+		return 0
+	}
+	return 1
+}
@@ -19,16 +19,107 @@ package metrics
 import (
 	"fmt"
 	"io"
+	"math"
 	"reflect"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	"k8s.io/kubernetes/pkg/util/sets"
+	"k8s.io/kubernetes/pkg/version"
 
 	"github.com/golang/glog"
 	"github.com/prometheus/common/expfmt"
 	"github.com/prometheus/common/model"
 )
 
+// KnownLabelValuePatterns constrains specific label names to a regexp their
+// value must match wherever that label appears, across any metric family in
+// any component. Unlike the KnownXMetrics maps, which only say a label name
+// is allowed, this catches malformed values of labels whose legal values
+// follow a simple pattern (e.g. a histogram's "le" bucket bound must be a
+// float or "+Inf") without having to enumerate every legal value up front.
+var KnownLabelValuePatterns = map[string]*regexp.Regexp{
+	"le":       regexp.MustCompile(`^(\+Inf|-?[0-9]+(\.[0-9]+)?)$`),
+	"quantile": regexp.MustCompile(`^(0(\.[0-9]+)?|1(\.0+)?)$`),
+	"code":     regexp.MustCompile(`^[1-5][0-9]{2}$`),
+}
+
+// KnownMetricsByVersion maps a component's release version, trimmed to
+// "major.minor" (e.g. "1.2"), to the known-metrics schema it exposes at that
+// version. It lets a single e2e binary validate a cluster with skewed
+// component versions against what each component's own version actually
+// exposes, rather than one schema for the whole cluster.
+type KnownMetricsByVersion map[string]map[string][]string
+
+// ResolveKnownMetrics returns the schema in byVersion for version, trimmed
+// to "major.minor", falling back to the "default" entry when byVersion has
+// no schema specific to that version -- e.g. because the metrics it exposes
+// haven't drifted since the default schema was recorded, or because the
+// version couldn't be determined at all.
+func ResolveKnownMetrics(byVersion KnownMetricsByVersion, version string) map[string][]string {
+	if schema, ok := byVersion[majorMinor(version)]; ok {
+		return schema
+	}
+	return byVersion["default"]
+}
+
+// majorMinor trims version (optionally "v"-prefixed, e.g. "v1.2.3-beta.0")
+// down to its "major.minor" component, or returns it unchanged if it
+// doesn't look like a dotted version string.
+func majorMinor(version string) string {
+	version = strings.TrimPrefix(version, "v")
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return version
+	}
+	return parts[0] + "." + parts[1]
+}
+
+// IsOlderMinorVersion reports whether componentVersion is an older release
+// than binaryVersion, by major or minor version, for deciding whether a
+// component running an older, supported-skew release should get relaxed
+// metrics validation instead of being held to the test binary's own
+// expected set. Either version failing to parse as a semver is treated as
+// "not older", since skew can't be determined.
+func IsOlderMinorVersion(componentVersion, binaryVersion string) bool {
+	cv, err := version.Parse(componentVersion)
+	if err != nil {
+		return false
+	}
+	bv, err := version.Parse(binaryVersion)
+	if err != nil {
+		return false
+	}
+	if cv.Major != bv.Major {
+		return cv.Major < bv.Major
+	}
+	return cv.Minor < bv.Minor
+}
+
+// InvalidLabelValues scans every sample in data for labels with a pattern
+// registered in KnownLabelValuePatterns, returning metric name -> set of
+// "label=value" strings whose value didn't match the expected pattern.
+func InvalidLabelValues(data Metrics) map[string]sets.String {
+	result := make(map[string]sets.String)
+	for metricName, samples := range data {
+		for _, sample := range samples {
+			for label, value := range sample.Metric {
+				pattern, ok := KnownLabelValuePatterns[string(label)]
+				if !ok || pattern.MatchString(string(value)) {
+					continue
+				}
+				if _, exists := result[metricName]; !exists {
+					result[metricName] = sets.NewString()
+				}
+				result[metricName].Insert(fmt.Sprintf("%s=%s", label, value))
+			}
+		}
+	}
+	return result
+}
+
 var CommonMetrics = map[string][]string{
 	"get_token_count":                          {},
 	"get_token_fail_count":                     {},
@@ -79,6 +170,362 @@ func (m *Metrics) Equal(o Metrics) bool {
 	return true
 }
 
+// String renders the snapshot with a deterministic ordering (metric name,
+// then sorted labels within each sample), so failure messages and artifact
+// diffs are readable and stable across runs instead of reflecting Go's
+// randomized map iteration order.
+func (m Metrics) String() string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buf := strings.Builder{}
+	for _, name := range names {
+		samples := append(model.Samples{}, m[name]...)
+		sort.Sort(samples)
+		for _, sample := range samples {
+			buf.WriteString(fmt.Sprintf("%v %v\n", name, PrintSample(sample)))
+		}
+	}
+	return buf.String()
+}
+
+// RuntimeMetricPrefixes holds the metric name prefixes that come from the
+// standard Go/Prometheus client instrumentation ("go_*", "process_*") rather
+// than from the component itself. Their values differ across binaries and
+// Go versions, which adds noise to known-metrics maintenance and diffs.
+var RuntimeMetricPrefixes = []string{"go_", "process_"}
+
+// isRuntimeMetric reports whether name belongs to the standard Go runtime or
+// process metric families rather than to the component being scraped.
+func isRuntimeMetric(name string) bool {
+	for _, prefix := range RuntimeMetricPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithoutRuntimeMetrics returns a copy of m with the Go runtime and process
+// metric families excluded. It defaults to off: callers of diff/report
+// tooling opt in explicitly, while code that needs resource-usage numbers
+// (e.g. process_resident_memory_bytes) can keep reading m directly.
+func (m Metrics) WithoutRuntimeMetrics() Metrics {
+	result := make(Metrics, len(m))
+	for name, samples := range m {
+		if isRuntimeMetric(name) {
+			continue
+		}
+		result[name] = samples
+	}
+	return result
+}
+
+// monotonicMetricSuffixes lists the metric name suffixes this package treats
+// as cumulative counters, so SubtractBaseline knows it's safe to diff them
+// sample-by-sample instead of just passing the latest value through.
+var monotonicMetricSuffixes = []string{"_total", "_count", "_sum"}
+
+func isMonotonicMetric(name string) bool {
+	for _, suffix := range monotonicMetricSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// latestValue returns the largest value among samples, along with false if
+// samples is empty. process_start_time_seconds is a gauge with one sample
+// per process; taking the max is just a defensive way to pick a value out
+// of a family that's only ever expected to carry one.
+func latestValue(samples model.Samples) (float64, bool) {
+	if len(samples) == 0 {
+		return 0, false
+	}
+	max := float64(samples[0].Value)
+	for _, sample := range samples[1:] {
+		if v := float64(sample.Value); v > max {
+			max = v
+		}
+	}
+	return max, true
+}
+
+// ProcessStartTime returns m's process_start_time_seconds reading as a
+// time.Time, along with false if m doesn't carry the metric (e.g. a
+// component that doesn't export it).
+func ProcessStartTime(m Metrics) (time.Time, bool) {
+	seconds, ok := latestValue(m["process_start_time_seconds"])
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(seconds), 0), true
+}
+
+// RestartedBetween reports whether current's process_start_time_seconds is
+// later than baseline's, meaning the component was restarted between the
+// two snapshots and its counters reset to zero rather than continuing to
+// accumulate. It returns false (no detected restart) if either snapshot is
+// missing the metric, e.g. a component that doesn't export it.
+func RestartedBetween(baseline, current Metrics) bool {
+	before, ok := ProcessStartTime(baseline)
+	if !ok {
+		return false
+	}
+	after, ok := ProcessStartTime(current)
+	if !ok {
+		return false
+	}
+	return after.After(before)
+}
+
+// SubtractBaseline returns a copy of m where every cumulative counter family
+// (names ending in _total, _count or _sum) has had the matching baseline
+// sample's value subtracted out, so a long-running suite can measure a
+// single phase's activity instead of the total accumulated since the
+// component started -- useful for components like the scheduler or kubelet
+// that, unlike the apiserver, have no /resetMetrics endpoint. Non-monotonic
+// metrics (gauges, summary quantiles) aren't diffable this way and are
+// passed through unchanged. Samples are matched to their baseline
+// counterpart by their full label set; a sample with no counterpart in
+// baseline is assumed to have started the phase at zero.
+//
+// If RestartedBetween(baseline, m) reports the component restarted during
+// the phase, every counter in m is already counting from zero, so m's
+// values are passed through unchanged instead of being (incorrectly)
+// subtracted from a baseline taken before the reset. Subtracting across a
+// per-series reset that isn't a whole-process restart is handled the same
+// way: a negative diff means the individual series reset, so its current
+// value -- not the negative difference -- is the phase's count from zero.
+func (m Metrics) SubtractBaseline(baseline Metrics) Metrics {
+	restarted := RestartedBetween(baseline, m)
+	result := make(Metrics, len(m))
+	for name, samples := range m {
+		if !isMonotonicMetric(name) || restarted {
+			result[name] = samples
+			continue
+		}
+		baselineByFingerprint := make(map[model.Fingerprint]model.SampleValue, len(baseline[name]))
+		for _, sample := range baseline[name] {
+			baselineByFingerprint[model.LabelSet(sample.Metric).Fingerprint()] = sample.Value
+		}
+		diffed := make(model.Samples, 0, len(samples))
+		for _, sample := range samples {
+			copied := *sample
+			diff := sample.Value - baselineByFingerprint[model.LabelSet(sample.Metric).Fingerprint()]
+			if diff < 0 {
+				diff = sample.Value
+			}
+			copied.Value = diff
+			diffed = append(diffed, &copied)
+		}
+		result[name] = diffed
+	}
+	return result
+}
+
+// FamilySet returns the names of the metric families in m that actually
+// carry samples, ignoring families a NewMetrics-style constructor
+// pre-populated empty. Useful for comparing two snapshots' shape rather
+// than their values.
+func FamilySet(m Metrics) sets.String {
+	result := sets.NewString()
+	for name, samples := range m {
+		if len(samples) > 0 {
+			result.Insert(name)
+		}
+	}
+	return result
+}
+
+// CompareFamilySets diffs two metrics snapshots' family sets, returning the
+// families that carried samples in only one of the two. It's meant for
+// comparing a component's secure and insecure metrics endpoints, which are
+// expected to serve the same registry even though the values from the two
+// scrapes will differ slightly from being taken a moment apart -- a
+// mismatched family set instead points at one handler serving a stale or
+// filtered registry.
+func CompareFamilySets(a, b Metrics) (onlyInA, onlyInB []string) {
+	setA, setB := FamilySet(a), FamilySet(b)
+	return setA.Difference(setB).List(), setB.Difference(setA).List()
+}
+
+// DropZeroSamples returns a copy of m with every zero-valued sample dropped
+// from each metric family. Many families export a zero-valued sample for
+// every label permutation that simply hasn't happened yet (e.g. every
+// verb/resource/code combination of apiserver_request_count), which bloats
+// written snapshots and drowns real changes in diff noise. A family whose
+// samples are all zero keeps its key in the result, mapped to an empty
+// model.Samples, so a reader can still tell the family was exported at all.
+func (m Metrics) DropZeroSamples() Metrics {
+	result := make(Metrics, len(m))
+	for name, samples := range m {
+		nonZero := make(model.Samples, 0, len(samples))
+		for _, sample := range samples {
+			if sample.Value != 0 {
+				nonZero = append(nonZero, sample)
+			}
+		}
+		result[name] = nonZero
+	}
+	return result
+}
+
+// ValuesByLabel indexes samples by the value of label, so callers can compare
+// a per-resource (or per-anything) gauge family across two snapshots without
+// re-deriving the grouping key themselves. Samples missing the label are
+// skipped.
+func ValuesByLabel(samples model.Samples, label string) map[string]float64 {
+	result := make(map[string]float64)
+	for _, sample := range samples {
+		value, ok := sample.Metric[model.LabelName(label)]
+		if !ok {
+			continue
+		}
+		result[string(value)] = float64(sample.Value)
+	}
+	return result
+}
+
+// SumBy aggregates metricName's samples by the given labelKeys, summing the
+// values of samples that share the same combination of label values. This
+// is the "sum by (labels) (metric)" PromQL idiom, e.g.
+// m.SumBy("apiserver_request_count", "verb") to total requests per verb
+// while ignoring resource/client/code -- nearly every metric-based
+// assertion needs some grouping like this, and without it callers each
+// hand-roll their own loop over the raw samples. The map is keyed by the
+// label values joined with "/", in the order labelKeys were given; samples
+// missing a key contribute an empty string for that position.
+func (m Metrics) SumBy(metricName string, labelKeys ...string) map[string]float64 {
+	result := make(map[string]float64)
+	for _, sample := range m[metricName] {
+		parts := make([]string, len(labelKeys))
+		for i, key := range labelKeys {
+			parts[i] = string(sample.Metric[model.LabelName(key)])
+		}
+		result[strings.Join(parts, "/")] += float64(sample.Value)
+	}
+	return result
+}
+
+// ValueAtQuantile returns the value of the sample in samples whose
+// "quantile" label equals quantile (e.g. "0.99"), for reading a
+// server-computed summary directly instead of a histogram bucket set. The
+// second return value is false if no sample carries that label value.
+func ValueAtQuantile(samples model.Samples, quantile string) (float64, bool) {
+	for _, sample := range samples {
+		if string(sample.Metric[model.LabelName("quantile")]) == quantile {
+			return float64(sample.Value), true
+		}
+	}
+	return 0, false
+}
+
+// SumValues adds up the values of samples, e.g. to collapse a per-label
+// counter family (apiserver_panic_count{...}) into a single total.
+func SumValues(samples model.Samples) float64 {
+	var sum float64
+	for _, sample := range samples {
+		sum += float64(sample.Value)
+	}
+	return sum
+}
+
+// SumAcrossInstances totals SumValues(samples) over perInstance, e.g. total
+// pod-start count across all kubelets. perInstance is typically built by a
+// caller pulling one metric family's samples out of each instance's
+// snapshot via the relevant component's accessor, keyed by instance name
+// (node, pod, ...). This is the cross-snapshot counterpart to SumValues,
+// which only sums within a single instance's samples.
+func SumAcrossInstances(perInstance map[string]model.Samples) float64 {
+	var sum float64
+	for _, samples := range perInstance {
+		sum += SumValues(samples)
+	}
+	return sum
+}
+
+// AverageAcrossInstances averages SumValues(samples) over the instances in
+// perInstance that actually reported any samples, so an instance that
+// hasn't scraped yet (an absent or empty model.Samples) doesn't drag the
+// average toward zero. The second return value is false if no instance
+// reported any samples.
+func AverageAcrossInstances(perInstance map[string]model.Samples) (float64, bool) {
+	var sum float64
+	var present int
+	for _, samples := range perInstance {
+		if len(samples) == 0 {
+			continue
+		}
+		sum += SumValues(samples)
+		present++
+	}
+	if present == 0 {
+		return 0, false
+	}
+	return sum / float64(present), true
+}
+
+// MeanAndStdDev returns the arithmetic mean and population standard
+// deviation of values, e.g. for MeasureStability to summarize a run of
+// repeated samples of one metric. Both are zero for an empty slice.
+func MeanAndStdDev(values []float64) (mean, stdDev float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean = sum / float64(len(values))
+
+	var sumSquares float64
+	for _, v := range values {
+		d := v - mean
+		sumSquares += d * d
+	}
+	return mean, math.Sqrt(sumSquares / float64(len(values)))
+}
+
+// topNSamples returns the n samples with the largest value, descending,
+// without mutating samples.
+func topNSamples(samples model.Samples, n int) model.Samples {
+	sorted := append(model.Samples{}, samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Value > sorted[j].Value })
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
+
+// TopN returns the n samples of metricName with the largest value in
+// snapshot, descending, for failure messages and anomaly reports that want
+// to show the worst offenders (e.g. "top 10 slowest resources by p99")
+// rather than just a formatted string -- see FormatTopSamples for that.
+func TopN(snapshot Metrics, metricName string, n int) model.Samples {
+	return topNSamples(snapshot[metricName], n)
+}
+
+// FormatTopSamples sorts samples by value, descending, and renders the top n
+// as one-per-line text suitable for embedding directly in a test failure
+// message, so triaging an SLO or invariant violation doesn't require
+// rerunning the test with extra logging just to see which series offended.
+func FormatTopSamples(samples model.Samples, n int) string {
+	if len(samples) == 0 {
+		return "(no samples)"
+	}
+	sorted := topNSamples(samples, n)
+	lines := make([]string, 0, len(sorted))
+	for _, sample := range sorted {
+		lines = append(lines, PrintSample(sample))
+	}
+	return strings.Join(lines, "\n")
+}
+
 func PrintSample(sample *model.Sample) string {
 	buf := make([]string, 0)
 	// Id is a VERY special label. For 'normal' container it's usless, but it's necessary
@@ -96,6 +543,7 @@ func PrintSample(sample *model.Sample) string {
 		}
 		buf = append(buf, fmt.Sprintf("%v=%v", string(k), v))
 	}
+	sort.Strings(buf)
 	return fmt.Sprintf("[%v] = %v", strings.Join(buf, ","), sample.Value)
 }
 
@@ -107,6 +555,13 @@ func NewMetrics() Metrics {
 	return result
 }
 
+// parseMetrics is the hot path behind every component's parseXMetrics: the
+// MetricsGrabber's kubelet fan-out alone calls it once per node per scrape,
+// decoding a whole exposition response line by line. Metrics.SubtractBaseline
+// (per-sample label-set fingerprinting) and ValueAtQuantile (a linear scan
+// per call) are this package's other scrape-frequency hot paths -- all three
+// are where a streaming parser or sample pooling would pay off first, so
+// they're the ones worth a benchmark before changing.
 func parseMetrics(data string, knownMetrics map[string][]string, output *Metrics, unknownMetrics sets.String) error {
 	dec, err := expfmt.NewDecoder(strings.NewReader(data), expfmt.FmtText)
 	if err != nil {
@@ -144,14 +599,43 @@ func parseMetrics(data string, knownMetrics map[string][]string, output *Metrics
 	return nil
 }
 
-func (g *MetricsGrabber) getMetricsFromPod(podName string, namespace string, port int) (string, error) {
-	rawOutput, err := g.client.Get().
+func (g *MetricsGrabber) getMetricsFromPod(podName string, namespace string, port int, timeout time.Duration) (string, error) {
+	if g.portForwardConfig != nil {
+		return g.getMetricsFromPodPortForward(podName, namespace, port, timeout)
+	}
+	req := g.withUserAgent(g.metricsClient.Get().
+		Prefix("proxy").
+		Namespace(namespace).
+		Resource("pods").
+		Name(fmt.Sprintf("%v:%v", podName, port)).
+		Suffix("metrics").
+		Timeout(timeout))
+	rawOutput, err := req.Do().Raw()
+	if err != nil {
+		return "", err
+	}
+	return string(rawOutput), nil
+}
+
+// getMetricsFromPodWithAccept is like getMetricsFromPod, but sets the
+// Accept header on the scrape request to accept, so a caller can request a
+// specific exposition format (e.g. protobuf) from a handler that
+// negotiates on it. Not supported when the grabber is configured to scrape
+// through a port-forward tunnel, since that path doesn't go through the
+// apiserver proxy's request builder.
+func (g *MetricsGrabber) getMetricsFromPodWithAccept(podName string, namespace string, port int, timeout time.Duration, accept string) (string, error) {
+	if g.portForwardConfig != nil {
+		return "", fmt.Errorf("getMetricsFromPodWithAccept: not supported with a port-forward configured grabber")
+	}
+	req := g.withUserAgent(g.metricsClient.Get().
 		Prefix("proxy").
 		Namespace(namespace).
 		Resource("pods").
 		Name(fmt.Sprintf("%v:%v", podName, port)).
 		Suffix("metrics").
-		Do().Raw()
+		SetHeader("Accept", accept).
+		Timeout(timeout))
+	rawOutput, err := req.Do().Raw()
 	if err != nil {
 		return "", err
 	}
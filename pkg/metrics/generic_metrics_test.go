@@ -0,0 +1,223 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+func counterSample(labels model.Metric, value float64) *model.Sample {
+	return &model.Sample{Metric: labels, Value: model.SampleValue(value)}
+}
+
+func processStartTimeSample(unixSeconds float64) *model.Sample {
+	return &model.Sample{
+		Metric: model.Metric{model.MetricNameLabel: "process_start_time_seconds"},
+		Value:  model.SampleValue(unixSeconds),
+	}
+}
+
+func TestInvalidLabelValues(t *testing.T) {
+	data := Metrics{
+		"apiserver_request_count": {
+			counterSample(model.Metric{"code": "200"}, 1),
+			counterSample(model.Metric{"code": "nope"}, 1),
+		},
+		"apiserver_request_latencies_bucket": {
+			counterSample(model.Metric{"le": "+Inf"}, 1),
+			counterSample(model.Metric{"le": "soon"}, 1),
+		},
+		"unrelated_metric": {
+			counterSample(model.Metric{"anything": "goes"}, 1),
+		},
+	}
+
+	invalid := InvalidLabelValues(data)
+	if len(invalid) != 2 {
+		t.Fatalf("invalid = %v, want entries for exactly 2 metric families", invalid)
+	}
+	if !invalid["apiserver_request_count"].Has("code=nope") {
+		t.Errorf("apiserver_request_count invalid values = %v, want code=nope", invalid["apiserver_request_count"])
+	}
+	if !invalid["apiserver_request_latencies_bucket"].Has("le=soon") {
+		t.Errorf("apiserver_request_latencies_bucket invalid values = %v, want le=soon", invalid["apiserver_request_latencies_bucket"])
+	}
+}
+
+func TestInvalidLabelValuesNoneInvalid(t *testing.T) {
+	data := Metrics{
+		"apiserver_request_count": {counterSample(model.Metric{"code": "404"}, 1)},
+	}
+	if invalid := InvalidLabelValues(data); len(invalid) != 0 {
+		t.Errorf("invalid = %v, want none", invalid)
+	}
+}
+
+func TestSubtractBaseline(t *testing.T) {
+	baseline := Metrics{
+		"apiserver_request_count":   {counterSample(model.Metric{"verb": "GET"}, 10)},
+		"process_start_time_seconds": {processStartTimeSample(1000)},
+	}
+	current := Metrics{
+		"apiserver_request_count":   {counterSample(model.Metric{"verb": "GET"}, 25)},
+		"process_start_time_seconds": {processStartTimeSample(1000)},
+	}
+
+	result := current.SubtractBaseline(baseline)
+	got := result["apiserver_request_count"]
+	if len(got) != 1 || got[0].Value != 15 {
+		t.Errorf("apiserver_request_count = %v, want a single sample of 15", got)
+	}
+}
+
+func TestSubtractBaselineNewSeriesStartsAtZero(t *testing.T) {
+	baseline := Metrics{}
+	current := Metrics{
+		"apiserver_request_count": {counterSample(model.Metric{"verb": "POST"}, 7)},
+	}
+
+	result := current.SubtractBaseline(baseline)
+	got := result["apiserver_request_count"]
+	if len(got) != 1 || got[0].Value != 7 {
+		t.Errorf("apiserver_request_count = %v, want a single sample of 7 (no baseline counterpart)", got)
+	}
+}
+
+func TestSubtractBaselineNonMonotonicPassesThrough(t *testing.T) {
+	baseline := Metrics{"apiserver_current_inflight_requests": {counterSample(nil, 50)}}
+	current := Metrics{"apiserver_current_inflight_requests": {counterSample(nil, 3)}}
+
+	result := current.SubtractBaseline(baseline)
+	got := result["apiserver_current_inflight_requests"]
+	if len(got) != 1 || got[0].Value != 3 {
+		t.Errorf("gauge family = %v, want the unmodified current value of 3", got)
+	}
+}
+
+func TestSubtractBaselineAcrossRestart(t *testing.T) {
+	baseline := Metrics{
+		"apiserver_request_count":    {counterSample(model.Metric{"verb": "GET"}, 1000)},
+		"process_start_time_seconds": {processStartTimeSample(1000)},
+	}
+	// The process restarted, so its counters -- and its start time -- reset.
+	current := Metrics{
+		"apiserver_request_count":    {counterSample(model.Metric{"verb": "GET"}, 5)},
+		"process_start_time_seconds": {processStartTimeSample(2000)},
+	}
+
+	result := current.SubtractBaseline(baseline)
+	got := result["apiserver_request_count"]
+	if len(got) != 1 || got[0].Value != 5 {
+		t.Errorf("apiserver_request_count after restart = %v, want the unmodified current value of 5", got)
+	}
+}
+
+func TestSubtractBaselinePerSeriesReset(t *testing.T) {
+	// No whole-process restart (process_start_time_seconds unchanged), but
+	// this one series' counter reset on its own -- e.g. it tracks a bounded
+	// cache that was cleared.
+	baseline := Metrics{
+		"apiserver_request_count":    {counterSample(model.Metric{"verb": "GET"}, 100)},
+		"process_start_time_seconds": {processStartTimeSample(1000)},
+	}
+	current := Metrics{
+		"apiserver_request_count":    {counterSample(model.Metric{"verb": "GET"}, 4)},
+		"process_start_time_seconds": {processStartTimeSample(1000)},
+	}
+
+	result := current.SubtractBaseline(baseline)
+	got := result["apiserver_request_count"]
+	if len(got) != 1 || got[0].Value != 4 {
+		t.Errorf("apiserver_request_count after a per-series reset = %v, want the unmodified current value of 4", got)
+	}
+}
+
+func TestSumBy(t *testing.T) {
+	m := Metrics{
+		"apiserver_request_count": {
+			counterSample(model.Metric{"verb": "GET", "resource": "pods"}, 3),
+			counterSample(model.Metric{"verb": "GET", "resource": "nodes"}, 2),
+			counterSample(model.Metric{"verb": "POST", "resource": "pods"}, 1),
+		},
+	}
+
+	got := m.SumBy("apiserver_request_count", "verb")
+	want := map[string]float64{"GET": 5, "POST": 1}
+	if len(got) != len(want) {
+		t.Fatalf("SumBy = %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("SumBy[%q] = %v, want %v", k, got[k], v)
+		}
+	}
+}
+
+func TestSumByMissingLabel(t *testing.T) {
+	m := Metrics{
+		"apiserver_request_count": {counterSample(model.Metric{"verb": "GET"}, 4)},
+	}
+	got := m.SumBy("apiserver_request_count", "verb", "resource")
+	if want := float64(4); got["GET/"] != want {
+		t.Errorf("SumBy with a missing label key = %v, want {\"GET/\": %v}", got, want)
+	}
+}
+
+func TestTopN(t *testing.T) {
+	snapshot := Metrics{
+		"apiserver_request_latencies": {
+			counterSample(model.Metric{"resource": "pods"}, 0.1),
+			counterSample(model.Metric{"resource": "nodes"}, 0.9),
+			counterSample(model.Metric{"resource": "events"}, 0.5),
+		},
+	}
+
+	top := TopN(snapshot, "apiserver_request_latencies", 2)
+	if len(top) != 2 {
+		t.Fatalf("got %d samples, want 2", len(top))
+	}
+	if top[0].Metric["resource"] != "nodes" || top[1].Metric["resource"] != "events" {
+		t.Errorf("TopN order = %v, want [nodes, events] descending by value", top)
+	}
+}
+
+func TestTopNFewerSamplesThanN(t *testing.T) {
+	snapshot := Metrics{"apiserver_request_latencies": {counterSample(model.Metric{"resource": "pods"}, 0.1)}}
+	if top := TopN(snapshot, "apiserver_request_latencies", 5); len(top) != 1 {
+		t.Errorf("got %d samples, want 1 (fewer samples than n)", len(top))
+	}
+}
+
+func TestProcessStartTime(t *testing.T) {
+	m := Metrics{"process_start_time_seconds": {processStartTimeSample(1609459200)}}
+	got, ok := ProcessStartTime(m)
+	if !ok {
+		t.Fatal("ProcessStartTime returned ok=false for a family with a sample")
+	}
+	if want := time.Unix(1609459200, 0); !got.Equal(want) {
+		t.Errorf("ProcessStartTime = %v, want %v", got, want)
+	}
+}
+
+func TestProcessStartTimeMissing(t *testing.T) {
+	if _, ok := ProcessStartTime(Metrics{}); ok {
+		t.Error("ProcessStartTime returned ok=true for a snapshot with no process_start_time_seconds family")
+	}
+}
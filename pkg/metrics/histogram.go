@@ -0,0 +1,209 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/common/model"
+)
+
+// histogramSeriesKey identifies one histogram series (i.e. one set of label
+// values excluding the "le" bucket boundary).
+func histogramSeriesKey(metric model.Metric) model.Fingerprint {
+	withoutLe := metric.Clone()
+	delete(withoutLe, model.LabelName("le"))
+	return withoutLe.Fingerprint()
+}
+
+// HistogramBucketDeltas converts two cumulative histogram snapshots (e.g.
+// "apiserver_request_latencies_bucket" samples gathered before and after a
+// test run) into per-bucket, non-cumulative counts observed between the two
+// snapshots. Prometheus histogram buckets are cumulative counters since
+// process start, so a naive diff of "end - baseline" per bucket still yields
+// a cumulative histogram for the window; this additionally subtracts
+// consecutive buckets so each entry reflects only the observations whose
+// value falls in that particular bucket's range.
+func HistogramBucketDeltas(baseline, end model.Samples) (model.Samples, error) {
+	type bucket struct {
+		le     float64
+		sample *model.Sample
+	}
+	baselineBySeries := make(map[model.Fingerprint]map[float64]float64)
+	for _, sample := range baseline {
+		le, err := bucketUpperBound(sample.Metric)
+		if err != nil {
+			return nil, err
+		}
+		key := histogramSeriesKey(sample.Metric)
+		if baselineBySeries[key] == nil {
+			baselineBySeries[key] = make(map[float64]float64)
+		}
+		baselineBySeries[key][le] = float64(sample.Value)
+	}
+
+	endBySeries := make(map[model.Fingerprint][]bucket)
+	for _, sample := range end {
+		le, err := bucketUpperBound(sample.Metric)
+		if err != nil {
+			return nil, err
+		}
+		key := histogramSeriesKey(sample.Metric)
+		endBySeries[key] = append(endBySeries[key], bucket{le: le, sample: sample})
+	}
+
+	result := make(model.Samples, 0, len(end))
+	for key, buckets := range endBySeries {
+		sort.Slice(buckets, func(i, j int) bool { return buckets[i].le < buckets[j].le })
+		prevCumulative := 0.0
+		for _, b := range buckets {
+			endVal := float64(b.sample.Value)
+			startVal := baselineBySeries[key][b.le]
+			cumulative := endVal - startVal
+			if cumulative < 0 {
+				// The counter was reset (e.g. component restart) between snapshots.
+				cumulative = endVal
+			}
+			perBucket := cumulative - prevCumulative
+			if perBucket < 0 {
+				perBucket = 0
+			}
+			prevCumulative = cumulative
+			result = append(result, &model.Sample{
+				Metric:    b.sample.Metric,
+				Value:     model.SampleValue(perBucket),
+				Timestamp: b.sample.Timestamp,
+			})
+		}
+	}
+	return result, nil
+}
+
+// EstimatePercentiles estimates, for each histogram series found in buckets,
+// the value below which the given quantile (0 <= quantile <= 1) of
+// observations fall. It uses the same linear-interpolation-within-bucket
+// approach as Prometheus's histogram_quantile() function, so the result is
+// only as precise as the bucket boundaries: two values that land in the same
+// bucket are indistinguishable. This lets SLO checks report a single
+// estimated pNN instead of "fell in bucket <=1s".
+func EstimatePercentiles(buckets model.Samples, quantile float64) (map[model.Fingerprint]float64, error) {
+	type bucket struct {
+		le    float64
+		count float64
+	}
+	bySeries := make(map[model.Fingerprint][]bucket)
+	for _, sample := range buckets {
+		le, err := bucketUpperBound(sample.Metric)
+		if err != nil {
+			return nil, err
+		}
+		key := histogramSeriesKey(sample.Metric)
+		bySeries[key] = append(bySeries[key], bucket{le: le, count: float64(sample.Value)})
+	}
+
+	result := make(map[model.Fingerprint]float64, len(bySeries))
+	for key, series := range bySeries {
+		sort.Slice(series, func(i, j int) bool { return series[i].le < series[j].le })
+		total := series[len(series)-1].count
+		if total == 0 {
+			result[key] = 0
+			continue
+		}
+		rank := quantile * total
+		bucketStart, bucketEnd, countStart, countEnd := 0.0, series[0].le, 0.0, series[0].count
+		for i, b := range series {
+			if b.count >= rank {
+				bucketEnd, countEnd = b.le, b.count
+				if i > 0 {
+					bucketStart, countStart = series[i-1].le, series[i-1].count
+				}
+				break
+			}
+			bucketStart, countStart = b.le, b.count
+		}
+		if math.IsInf(bucketEnd, 1) {
+			// The quantile falls in the +Inf bucket, so there's no upper
+			// bound to interpolate against.
+			result[key] = bucketStart
+			continue
+		}
+		if countEnd == countStart {
+			result[key] = bucketEnd
+			continue
+		}
+		fraction := (rank - countStart) / (countEnd - countStart)
+		result[key] = bucketStart + fraction*(bucketEnd-bucketStart)
+	}
+	return result, nil
+}
+
+// barChartWidth is the widest bar RenderBarChart draws, in characters.
+const barChartWidth = 50
+
+// RenderBarChart renders one histogram series' non-cumulative per-bucket
+// counts (e.g. the output of HistogramBucketDeltas) as a text bar chart, one
+// line per bucket, so an SLO failure's Logf output shows the shape of the
+// latency distribution and not just the percentile that tripped it.
+func RenderBarChart(perBucket model.Samples) (string, error) {
+	type bucket struct {
+		le    float64
+		count float64
+	}
+	buckets := make([]bucket, 0, len(perBucket))
+	max := 0.0
+	for _, sample := range perBucket {
+		le, err := bucketUpperBound(sample.Metric)
+		if err != nil {
+			return "", err
+		}
+		count := float64(sample.Value)
+		buckets = append(buckets, bucket{le: le, count: count})
+		if count > max {
+			max = count
+		}
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].le < buckets[j].le })
+
+	buf := &bytes.Buffer{}
+	for _, b := range buckets {
+		label := fmt.Sprintf("<=%v", b.le)
+		if math.IsInf(b.le, 1) {
+			label = "+Inf"
+		}
+		bars := 0
+		if max > 0 {
+			bars = int(b.count / max * barChartWidth)
+		}
+		fmt.Fprintf(buf, "%12s |%s %v\n", label, strings.Repeat("#", bars), b.count)
+	}
+	return buf.String(), nil
+}
+
+// bucketUpperBound extracts and parses the "le" label, which Prometheus uses
+// to mark the inclusive upper bound of a histogram bucket.
+func bucketUpperBound(metric model.Metric) (float64, error) {
+	raw, ok := metric[model.LabelName("le")]
+	if !ok {
+		return 0, fmt.Errorf("sample %v has no %q label", metric, "le")
+	}
+	return strconv.ParseFloat(string(raw), 64)
+}
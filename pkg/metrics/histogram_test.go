@@ -0,0 +1,161 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/common/model"
+)
+
+func bucketSample(le string, value float64) *model.Sample {
+	return &model.Sample{
+		Metric: model.Metric{
+			model.MetricNameLabel: "test_latency_bucket",
+			"le":                  model.LabelValue(le),
+		},
+		Value: model.SampleValue(value),
+	}
+}
+
+func TestHistogramBucketDeltas(t *testing.T) {
+	baseline := model.Samples{
+		bucketSample("0.1", 10),
+		bucketSample("1", 20),
+		bucketSample("+Inf", 20),
+	}
+	end := model.Samples{
+		bucketSample("0.1", 15),
+		bucketSample("1", 40),
+		bucketSample("+Inf", 45),
+	}
+
+	deltas, err := HistogramBucketDeltas(baseline, end)
+	if err != nil {
+		t.Fatalf("HistogramBucketDeltas: %v", err)
+	}
+	if len(deltas) != 3 {
+		t.Fatalf("got %d deltas, want 3", len(deltas))
+	}
+
+	perBucket := make(map[string]float64, len(deltas))
+	for _, sample := range deltas {
+		perBucket[string(sample.Metric["le"])] = float64(sample.Value)
+	}
+	want := map[string]float64{
+		"0.1":  5,  // 15 - 10
+		"1":    15, // (40 - 10) - 5
+		"+Inf": 5,  // (45 - 20) - 15
+	}
+	for le, wantValue := range want {
+		if got := perBucket[le]; got != wantValue {
+			t.Errorf("bucket %q = %v, want %v", le, got, wantValue)
+		}
+	}
+}
+
+func TestHistogramBucketDeltasCounterReset(t *testing.T) {
+	baseline := model.Samples{
+		bucketSample("0.1", 100),
+		bucketSample("+Inf", 200),
+	}
+	// The component restarted between snapshots, so end's cumulative counts
+	// are smaller than baseline's despite genuinely reflecting more samples.
+	end := model.Samples{
+		bucketSample("0.1", 3),
+		bucketSample("+Inf", 5),
+	}
+
+	deltas, err := HistogramBucketDeltas(baseline, end)
+	if err != nil {
+		t.Fatalf("HistogramBucketDeltas: %v", err)
+	}
+	perBucket := make(map[string]float64, len(deltas))
+	for _, sample := range deltas {
+		perBucket[string(sample.Metric["le"])] = float64(sample.Value)
+	}
+	if got := perBucket["0.1"]; got != 3 {
+		t.Errorf("bucket 0.1 = %v, want 3 (end value, counter reset detected)", got)
+	}
+	if got := perBucket["+Inf"]; got != 2 {
+		t.Errorf("bucket +Inf = %v, want 2 (5 - 3)", got)
+	}
+}
+
+func TestHistogramBucketDeltasMissingLeLabel(t *testing.T) {
+	bad := model.Samples{{Metric: model.Metric{model.MetricNameLabel: "test_latency_bucket"}, Value: 1}}
+	if _, err := HistogramBucketDeltas(bad, bad); err == nil {
+		t.Error("expected an error for a sample missing the le label, got nil")
+	}
+}
+
+func TestEstimatePercentiles(t *testing.T) {
+	buckets := model.Samples{
+		bucketSample("0.1", 0),
+		bucketSample("0.5", 80),
+		bucketSample("1", 95),
+		bucketSample("+Inf", 100),
+	}
+
+	results, err := EstimatePercentiles(buckets, 0.9)
+	if err != nil {
+		t.Fatalf("EstimatePercentiles: %v", err)
+	}
+	key := histogramSeriesKey(buckets[0].Metric)
+	got, ok := results[key]
+	if !ok {
+		t.Fatalf("no estimate for the series found")
+	}
+	// rank = 0.9*100 = 90, which falls between the 0.5 bucket (80) and the 1
+	// bucket (95): 0.5 + (90-80)/(95-80)*(1-0.5) = 0.6666...
+	if want := 0.5 + (90.0-80.0)/(95.0-80.0)*(1-0.5); got < want-1e-9 || got > want+1e-9 {
+		t.Errorf("p90 = %v, want %v", got, want)
+	}
+}
+
+func TestEstimatePercentilesInInfBucket(t *testing.T) {
+	buckets := model.Samples{
+		bucketSample("0.1", 95),
+		bucketSample("+Inf", 100),
+	}
+	results, err := EstimatePercentiles(buckets, 0.99)
+	if err != nil {
+		t.Fatalf("EstimatePercentiles: %v", err)
+	}
+	key := histogramSeriesKey(buckets[0].Metric)
+	// rank = 99, which only falls in the +Inf bucket -- there's no upper
+	// bound to interpolate against, so the estimate is the previous
+	// bucket's boundary.
+	if got, want := results[key], 0.1; got != want {
+		t.Errorf("p99 = %v, want %v", got, want)
+	}
+}
+
+func TestEstimatePercentilesNoSamples(t *testing.T) {
+	buckets := model.Samples{
+		bucketSample("0.1", 0),
+		bucketSample("+Inf", 0),
+	}
+	results, err := EstimatePercentiles(buckets, 0.5)
+	if err != nil {
+		t.Fatalf("EstimatePercentiles: %v", err)
+	}
+	key := histogramSeriesKey(buckets[0].Metric)
+	if got, want := results[key], 0.0; got != want {
+		t.Errorf("estimate for an empty histogram = %v, want %v", got, want)
+	}
+}
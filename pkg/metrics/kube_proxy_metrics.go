@@ -0,0 +1,64 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"k8s.io/kubernetes/pkg/util/sets"
+
+	"github.com/prometheus/common/model"
+)
+
+var KnownKubeProxyMetrics = map[string][]string{
+	"kubeproxy_sync_proxy_rules_failure_total": {},
+	"kubeproxy_iptables_rules_total":           {},
+}
+
+type KubeProxyMetrics Metrics
+
+func (m *KubeProxyMetrics) Equal(o KubeProxyMetrics) bool {
+	return (*Metrics)(m).Equal(Metrics(o))
+}
+
+func NewKubeProxyMetrics() KubeProxyMetrics {
+	result := NewMetrics()
+	for metric := range KnownKubeProxyMetrics {
+		result[metric] = make(model.Samples, 0)
+	}
+	return KubeProxyMetrics(result)
+}
+
+// SyncProxyRulesFailuresCount returns the samples for the iptables proxier's
+// failed-restore counter, so callers don't need to know the raw metric name
+// to get at it.
+func (m KubeProxyMetrics) SyncProxyRulesFailuresCount() model.Samples {
+	return m["kubeproxy_sync_proxy_rules_failure_total"]
+}
+
+// IptablesRulesTotal returns the samples for the iptables proxier's owned
+// rule-count gauge, so callers don't need to know the raw metric name to get
+// at it.
+func (m KubeProxyMetrics) IptablesRulesTotal() model.Samples {
+	return m["kubeproxy_iptables_rules_total"]
+}
+
+func parseKubeProxyMetrics(data string, unknownMetrics sets.String) (KubeProxyMetrics, error) {
+	result := NewKubeProxyMetrics()
+	if err := parseMetrics(data, KnownKubeProxyMetrics, (*Metrics)(&result), unknownMetrics); err != nil {
+		return KubeProxyMetrics{}, err
+	}
+	return result, nil
+}
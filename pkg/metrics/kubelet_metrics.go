@@ -18,7 +18,16 @@ package metrics
 
 import (
 	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/client/transport"
+	"k8s.io/kubernetes/pkg/master/ports"
+	nodeutil "k8s.io/kubernetes/pkg/util/node"
 	"k8s.io/kubernetes/pkg/util/sets"
 
 	"github.com/prometheus/common/model"
@@ -26,9 +35,12 @@ import (
 
 var KnownKubeletMetrics = map[string][]string{
 	"cadvisor_version_info":                                  {"cadvisorRevision", "cadvisorVersion", "dockerVersion", "kernelVersion", "osVersion"},
+	"container_cpu_load_average_10s":                         {"id", "image", "kubernetes_container_name", "kubernetes_namespace", "kubernetes_pod_name", "name"},
 	"container_cpu_system_seconds_total":                     {"id", "image", "kubernetes_container_name", "kubernetes_namespace", "kubernetes_pod_name", "name"},
 	"container_cpu_usage_seconds_total":                      {"id", "image", "kubernetes_container_name", "kubernetes_namespace", "kubernetes_pod_name", "name", "cpu"},
 	"container_cpu_user_seconds_total":                       {"id", "image", "kubernetes_container_name", "kubernetes_namespace", "kubernetes_pod_name", "name"},
+	"container_fs_inodes_free":                               {"device", "id", "image", "kubernetes_container_name", "kubernetes_namespace", "kubernetes_pod_name", "name"},
+	"container_fs_inodes_total":                              {"device", "id", "image", "kubernetes_container_name", "kubernetes_namespace", "kubernetes_pod_name", "name"},
 	"container_fs_io_current":                                {"device", "id", "image", "kubernetes_container_name", "kubernetes_namespace", "kubernetes_pod_name", "name"},
 	"container_fs_io_time_seconds_total":                     {"device", "id", "image", "kubernetes_container_name", "kubernetes_namespace", "kubernetes_pod_name", "name"},
 	"container_fs_io_time_weighted_seconds_total":            {"device", "id", "image", "kubernetes_container_name", "kubernetes_namespace", "kubernetes_pod_name", "name"},
@@ -43,8 +55,12 @@ var KnownKubeletMetrics = map[string][]string{
 	"container_fs_writes_merged_total":                       {"device", "id", "image", "kubernetes_container_name", "kubernetes_namespace", "kubernetes_pod_name", "name"},
 	"container_fs_writes_total":                              {"device", "id", "image", "kubernetes_container_name", "kubernetes_namespace", "kubernetes_pod_name", "name"},
 	"container_last_seen":                                    {"id", "image", "kubernetes_container_name", "kubernetes_namespace", "kubernetes_pod_name", "name"},
+	"container_memory_cache":                                 {"id", "image", "kubernetes_container_name", "kubernetes_namespace", "kubernetes_pod_name", "name"},
 	"container_memory_failcnt":                               {"id", "image", "kubernetes_container_name", "kubernetes_namespace", "kubernetes_pod_name", "name"},
 	"container_memory_failures_total":                        {"id", "image", "kubernetes_container_name", "kubernetes_namespace", "kubernetes_pod_name", "name", "scope", "type"},
+	"container_memory_mapped_file":                           {"id", "image", "kubernetes_container_name", "kubernetes_namespace", "kubernetes_pod_name", "name"},
+	"container_memory_rss":                                   {"id", "image", "kubernetes_container_name", "kubernetes_namespace", "kubernetes_pod_name", "name"},
+	"container_memory_swap":                                  {"id", "image", "kubernetes_container_name", "kubernetes_namespace", "kubernetes_pod_name", "name"},
 	"container_memory_usage_bytes":                           {"id", "image", "kubernetes_container_name", "kubernetes_namespace", "kubernetes_pod_name", "name"},
 	"container_memory_working_set_bytes":                     {"id", "image", "kubernetes_container_name", "kubernetes_namespace", "kubernetes_pod_name", "name"},
 	"container_network_receive_bytes_total":                  {"id", "interface", "image", "kubernetes_container_name", "kubernetes_namespace", "kubernetes_pod_name", "name"},
@@ -56,6 +72,8 @@ var KnownKubeletMetrics = map[string][]string{
 	"container_network_transmit_packets_dropped_total":       {"id", "interface", "image", "kubernetes_container_name", "kubernetes_namespace", "kubernetes_pod_name", "name"},
 	"container_network_transmit_packets_total":               {"id", "interface", "image", "kubernetes_container_name", "kubernetes_namespace", "kubernetes_pod_name", "name"},
 	"container_scrape_error":                                 {},
+	"container_spec_cpu_period":                              {"id", "image", "kubernetes_container_name", "kubernetes_namespace", "kubernetes_pod_name", "name"},
+	"container_spec_cpu_quota":                               {"id", "image", "kubernetes_container_name", "kubernetes_namespace", "kubernetes_pod_name", "name"},
 	"container_spec_cpu_shares":                              {"id", "image", "kubernetes_container_name", "kubernetes_namespace", "kubernetes_pod_name", "name"},
 	"container_spec_memory_limit_bytes":                      {"id", "image", "kubernetes_container_name", "kubernetes_namespace", "kubernetes_pod_name", "name"},
 	"container_spec_memory_swap_limit_bytes":                 {"id", "image", "kubernetes_container_name", "kubernetes_namespace", "kubernetes_pod_name", "name"},
@@ -68,6 +86,8 @@ var KnownKubeletMetrics = map[string][]string{
 	"kubelet_containers_per_pod_count_count":                 {},
 	"kubelet_containers_per_pod_count_sum":                   {},
 	"kubelet_docker_errors":                                  {"operation_type"},
+	"kubelet_eviction_count":                                 {"signal"},
+	"kubelet_eviction_stats_age_microseconds":                {"signal", "quantile"},
 	"kubelet_docker_operations_latency_microseconds":         {"operation_type", "quantile"},
 	"kubelet_docker_operations_latency_microseconds_count":   {"operation_type"},
 	"kubelet_docker_operations_latency_microseconds_sum":     {"operation_type"},
@@ -89,6 +109,9 @@ var KnownKubeletMetrics = map[string][]string{
 	"kubelet_pod_worker_start_latency_microseconds":          {"quantile"},
 	"kubelet_pod_worker_start_latency_microseconds_count":    {},
 	"kubelet_pod_worker_start_latency_microseconds_sum":      {},
+	"kubelet_volume_mount_latency_microseconds":              {"plugin_name", "quantile"},
+	"kubelet_volume_mount_latency_microseconds_count":        {"plugin_name"},
+	"kubelet_volume_mount_latency_microseconds_sum":          {"plugin_name"},
 	"kubelet_running_container_count":                        {},
 	"kubelet_running_pod_count":                              {},
 	"kubelet_sync_pods_latency_microseconds":                 {"quantile"},
@@ -102,6 +125,14 @@ var KnownKubeletMetrics = map[string][]string{
 	"rest_client_request_status_codes":                       {"code", "host", "method"},
 }
 
+// KnownKubeletMetricsByVersion resolves the known-metrics schema for a
+// specific kubelet release via ResolveKnownMetrics. It currently has only a
+// "default" entry; add a "major.minor" entry here once a release is found
+// to have actually added or removed kubelet metric families.
+var KnownKubeletMetricsByVersion = KnownMetricsByVersion{
+	"default": KnownKubeletMetrics,
+}
+
 var KubeletMetricsLabelsToSkip = sets.NewString(
 	"kubernetes_namespace",
 	"image",
@@ -122,23 +153,215 @@ func NewKubeletMetrics() KubeletMetrics {
 	return KubeletMetrics(result)
 }
 
+// RunningPodCount returns the samples for the kubelet's running pod count gauge,
+// so callers don't need to know the raw metric name.
+func (m KubeletMetrics) RunningPodCount() model.Samples {
+	return m["kubelet_running_pod_count"]
+}
+
+// RelistInterval returns the samples for the PLEG relist interval summary,
+// so callers don't need to know the raw metric name to get at it.
+func (m KubeletMetrics) RelistInterval() model.Samples {
+	return m["kubelet_pleg_relist_interval_microseconds"]
+}
+
+// EvictionCount returns the samples for the kubelet's per-signal eviction
+// counter, so callers don't need to know the raw metric name to get at it.
+func (m KubeletMetrics) EvictionCount() model.Samples {
+	return m["kubelet_eviction_count"]
+}
+
+// VolumeMountLatencyCount returns the samples for the kubelet's per-plugin
+// volume mount latency sample count, so callers don't need to know the raw
+// metric name to get at it.
+func (m KubeletMetrics) VolumeMountLatencyCount() model.Samples {
+	return m["kubelet_volume_mount_latency_microseconds_count"]
+}
+
+// SyncPodsLatency returns the samples for the kubelet's sync loop duration
+// summary (how long one SyncPods pass over the pod worklist took), so
+// callers don't need to know the raw metric name to get at it. Sync loop
+// stalls are a leading indicator of NodeNotReady flakes, since a kubelet
+// that can't finish a sync pass can't refresh its own node status in time.
+func (m KubeletMetrics) SyncPodsLatency() model.Samples {
+	return m["kubelet_sync_pods_latency_microseconds"]
+}
+
+// PodStartCount returns the samples for the kubelet's pod-start latency
+// sample count, i.e. how many pods this kubelet has finished starting, so
+// callers don't need to know the raw metric name to get at it.
+func (m KubeletMetrics) PodStartCount() model.Samples {
+	return m["kubelet_pod_start_latency_microseconds_count"]
+}
+
+// DockerErrorsCount returns the samples for the kubelet's per-operation
+// docker/runtime error counter, so callers don't need to know the raw
+// metric name to get at it.
+func (m KubeletMetrics) DockerErrorsCount() model.Samples {
+	return m["kubelet_docker_errors"]
+}
+
 func parseKubeletMetrics(data string, unknownMetrics sets.String) (KubeletMetrics, error) {
+	return parseKubeletMetricsWithSchema(data, KnownKubeletMetrics, unknownMetrics)
+}
+
+func parseKubeletMetricsWithSchema(data string, knownMetrics map[string][]string, unknownMetrics sets.String) (KubeletMetrics, error) {
 	result := NewKubeletMetrics()
-	if err := parseMetrics(data, KnownKubeletMetrics, (*Metrics)(&result), unknownMetrics); err != nil {
+	if err := parseMetrics(data, knownMetrics, (*Metrics)(&result), unknownMetrics); err != nil {
 		return KubeletMetrics{}, err
 	}
 	return result, nil
 }
 
-func (g *MetricsGrabber) getMetricsFromNode(nodeName string, kubeletPort int) (string, error) {
-	rawOutput, err := g.client.Get().
+// IsWindowsNode reports whether node's kubelet is believed to be running on
+// Windows. This API version has no dedicated OS field, so it's a heuristic
+// over NodeSystemInfo.OSImage (e.g. "Windows Server 2016 Datacenter");
+// anything that doesn't match is treated as Linux.
+func IsWindowsNode(node *api.Node) bool {
+	return strings.Contains(strings.ToLower(node.Status.NodeInfo.OSImage), "windows")
+}
+
+// ExpectedKubeletMetrics returns the metric-family/label schema a kubelet
+// scrape on node is expected to match, so GrabFromAllKubelets can validate
+// Windows nodes against a Windows-appropriate set instead of flagging
+// legitimate OS differences as unrecognized metrics.
+func ExpectedKubeletMetrics(node *api.Node) map[string][]string {
+	if IsWindowsNode(node) {
+		return KnownKubeletMetricsWindows
+	}
+	return KnownKubeletMetrics
+}
+
+// KnownKubeletMetricsWindows is KnownKubeletMetrics with the families that
+// depend on Linux cgroups and the Docker overlay/devicemapper storage
+// drivers removed, since the Windows cAdvisor stats provider doesn't expose
+// them. This is a starting point, not a verified-complete list; extend it
+// as real Windows-node runs turn up further divergence.
+var KnownKubeletMetricsWindows = func() map[string][]string {
+	excluded := sets.NewString(
+		"container_fs_inodes_free",
+		"container_fs_inodes_total",
+		"container_fs_io_current",
+		"container_fs_io_time_seconds_total",
+		"container_fs_io_time_weighted_seconds_total",
+		"container_fs_limit_bytes",
+		"container_fs_read_seconds_total",
+		"container_fs_reads_merged_total",
+		"container_fs_reads_total",
+		"container_fs_sector_reads_total",
+		"container_fs_sector_writes_total",
+		"container_fs_write_seconds_total",
+		"container_fs_writes_merged_total",
+		"container_fs_writes_total",
+	)
+	result := make(map[string][]string, len(KnownKubeletMetrics))
+	for name, labels := range KnownKubeletMetrics {
+		if excluded.Has(name) {
+			continue
+		}
+		result[name] = labels
+	}
+	return result
+}()
+
+// getMetricsFromNodeProxy scrapes nodeName's kubelet through the apiserver's
+// node proxy subresource. This needs no credentials beyond the ones the
+// grabber already authenticates to the apiserver with, but some providers
+// (e.g. GKE, see SupportsKubeletProxyScrape in test/e2e) block the proxy
+// subresource outright, and it's known to flake under load even where it's
+// allowed (#19468) -- grabFromKubeletInternal only relies on it as the first
+// of several scrape strategies, not the only one.
+func (g *MetricsGrabber) getMetricsFromNodeProxy(nodeName string, kubeletPort int, timeout time.Duration) (string, error) {
+	req := g.withUserAgent(g.metricsClient.Get().
 		Prefix("proxy").
 		Resource("nodes").
 		Name(fmt.Sprintf("%v:%v", nodeName, kubeletPort)).
 		Suffix("metrics").
-		Do().Raw()
+		Timeout(timeout))
+	rawOutput, err := req.Do().Raw()
 	if err != nil {
 		return "", err
 	}
 	return string(rawOutput), nil
 }
+
+// getMetricsFromNodeDirect scrapes nodeName's kubelet directly on its secure
+// port, instead of going through the apiserver's node proxy subresource.
+// Used as a fallback on providers where the proxy subresource isn't
+// available or is unreliable, authenticating with g.kubeletBearerToken when
+// one is configured (as it is for grabbers built with
+// NewTokenAuthMetricsGrabber). The kubelet's serving certificate is
+// typically self-signed or issued by a CA the client doesn't carry, so by
+// default this connection can't be verified the way apiserver connections
+// are; callers that care set MetricsGrabberOptions.DirectScrapeTLS.CAFile
+// (or .Insecure, for lab clusters) to control that tradeoff explicitly.
+func (g *MetricsGrabber) getMetricsFromNodeDirect(nodeName string, kubeletPort int, timeout time.Duration) (string, ScrapePhaseTiming, error) {
+	node, err := g.client.Nodes().Get(nodeName)
+	if err != nil {
+		return "", ScrapePhaseTiming{}, err
+	}
+	hostIP, err := nodeutil.GetNodeHostIP(node)
+	if err != nil {
+		return "", ScrapePhaseTiming{}, err
+	}
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("https://%v/metrics", net.JoinHostPort(hostIP.String(), strconv.Itoa(kubeletPort))), nil)
+	if err != nil {
+		return "", ScrapePhaseTiming{}, err
+	}
+	if g.kubeletBearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+g.kubeletBearerToken)
+	}
+	if g.options.UserAgent != "" {
+		req.Header.Set("User-Agent", g.options.UserAgent)
+	}
+
+	tlsConfig, err := transport.TLSConfigFor(&transport.Config{TLS: g.options.DirectScrapeTLS})
+	if err != nil {
+		return "", ScrapePhaseTiming{}, err
+	}
+	httpClient := &http.Client{
+		Timeout:   timeout,
+		Transport: &http.Transport{TLSClientConfig: tlsConfig},
+	}
+	output, timing, err := timedHTTPGet(httpClient, req)
+	if err != nil {
+		return "", timing, fmt.Errorf("scraping kubelet %v directly: %v", nodeName, err)
+	}
+	return output, timing, nil
+}
+
+// getMetricsFromNodeReadOnly scrapes nodeName's kubelet on its insecure,
+// unauthenticated read-only port (ports.KubeletReadOnlyPort, 10255) over
+// plain HTTP, directly rather than through the apiserver. This is the last
+// resort in grabFromKubeletInternal's scrape strategy chain: it needs
+// neither a working proxy subresource nor any scrape credentials, but it
+// only works where the cluster hasn't disabled the read-only port, and the
+// resulting registry can differ slightly from the secure port's (see
+// CompareKubeletEndpoints).
+func (g *MetricsGrabber) getMetricsFromNodeReadOnly(nodeName string, timeout time.Duration) (string, ScrapePhaseTiming, error) {
+	node, err := g.client.Nodes().Get(nodeName)
+	if err != nil {
+		return "", ScrapePhaseTiming{}, err
+	}
+	hostIP, err := nodeutil.GetNodeHostIP(node)
+	if err != nil {
+		return "", ScrapePhaseTiming{}, err
+	}
+
+	url := fmt.Sprintf("http://%v/metrics", net.JoinHostPort(hostIP.String(), strconv.Itoa(ports.KubeletReadOnlyPort)))
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return "", ScrapePhaseTiming{}, err
+	}
+	if g.options.UserAgent != "" {
+		req.Header.Set("User-Agent", g.options.UserAgent)
+	}
+
+	httpClient := &http.Client{Timeout: timeout}
+	output, timing, err := timedHTTPGet(httpClient, req)
+	if err != nil {
+		return "", timing, fmt.Errorf("scraping kubelet %v's read-only port: %v", nodeName, err)
+	}
+	return output, timing, nil
+}
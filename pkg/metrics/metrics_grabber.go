@@ -17,41 +17,542 @@ limitations under the License.
 package metrics
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptrace"
 	"strings"
+	"sync"
+	"time"
 
 	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/client/leaderelection"
+	"k8s.io/kubernetes/pkg/client/transport"
 	client "k8s.io/kubernetes/pkg/client/unversioned"
+	"k8s.io/kubernetes/pkg/client/unversioned/clientcmd"
+	"k8s.io/kubernetes/pkg/client/unversioned/portforward"
+	"k8s.io/kubernetes/pkg/client/unversioned/remotecommand"
 	"k8s.io/kubernetes/pkg/fields"
+	"k8s.io/kubernetes/pkg/labels"
 	"k8s.io/kubernetes/pkg/master/ports"
+	"k8s.io/kubernetes/pkg/util"
 	"k8s.io/kubernetes/pkg/util/sets"
+	"k8s.io/kubernetes/pkg/util/wait"
 
 	"github.com/golang/glog"
 )
 
+// readinessWaitTimeout and readinessPollInterval bound how long the grabber
+// waits for a component's healthz to turn green before scraping it, so
+// suites that run right after cluster bring-up or a component restart
+// don't record spurious scrape failures for components that are still
+// starting.
+const (
+	readinessWaitTimeout  = 1 * time.Minute
+	readinessPollInterval = 2 * time.Second
+)
+
+// NamespaceKubemark is the namespace hollow-node pods run in on the
+// kubemark "external" cluster.
+const NamespaceKubemark = "kubemark"
+
+// MetricsGrabberOptions sets a per-component scrape timeout, since
+// different components' metrics endpoints have very different response
+// time budgets -- a kubelet on a busy node can legitimately take much
+// longer to answer than the scheduler -- and a single client-wide timeout
+// can't accommodate both without being too loose for some components or
+// too tight for others.
+type MetricsGrabberOptions struct {
+	ApiServerTimeout         time.Duration
+	ControllerManagerTimeout time.Duration
+	KubeletTimeout           time.Duration
+	SchedulerTimeout         time.Duration
+	// DefaultTimeout applies to add-on components (addon-manager, dns,
+	// federation, rescheduler) that don't have a dedicated field above.
+	DefaultTimeout time.Duration
+	// Parallelism bounds how many kubelets GrabFromAllKubelets scrapes
+	// concurrently. A large cluster needs this above 1 to grab in
+	// reasonable time; a small test master's apiserver proxy can be
+	// overwhelmed if it's set too high.
+	Parallelism int
+	// ScrapeRetries bounds how many additional attempts a single component
+	// scrape gets after a transient error (e.g. a kubelet proxy timeout)
+	// before it's reported as failed. Every retry is counted in the
+	// grabber's FlakeReport, so chronic flakiness on one path shows up in
+	// a suite-level report instead of only as scattered per-test log
+	// noise.
+	ScrapeRetries int
+	// ScrapeDurationBudgets optionally bounds how long a single
+	// component's scrape (across every attempt, if ScrapeRetries made it
+	// retry) may take, keyed by the ComponentXxx constants. A scrape that
+	// otherwise succeeds but exceeds its budget is reported as a failure,
+	// since a metrics handler slow enough to blow past a Prometheus
+	// scrape timeout in production is exactly the regression this is
+	// meant to catch. Components with no entry are unbounded.
+	ScrapeDurationBudgets map[string]time.Duration
+	// UserAgent, if set, overrides the User-Agent header on every scrape
+	// request the grabber makes, so its own traffic is distinguishable from
+	// other clients' in apiserver logs and in the "client" label of
+	// apiserver_request_count -- useful for excluding the grabber's own
+	// load from a per-test API-call attribution report. Left empty, scrape
+	// requests go out with whatever User-Agent the underlying client was
+	// already configured with.
+	UserAgent string
+	// KubeletScrapeQPS, if positive, bounds how often this grabber will
+	// scrape any single kubelet, smoothed across every caller sharing it --
+	// both a periodic Sampler and any number of ad hoc per-test grabs --
+	// so they can't collectively hammer a kubelet on a small test node.
+	// KubeletScrapeBurst allows short bursts above that steady rate before
+	// throttling kicks in. Zero (the default) leaves kubelet scraping
+	// unthrottled.
+	KubeletScrapeQPS   float32
+	KubeletScrapeBurst int
+	// DirectScrapeTLS configures the TLS transport used for direct,
+	// bearer-token-authenticated kubelet scrapes (see
+	// NewTokenAuthMetricsGrabber); it has no effect when scraping goes
+	// through the apiserver's node proxy subresource, which uses the
+	// apiserver client's own TLS configuration instead. The zero value
+	// verifies the kubelet's serving certificate against the system root
+	// CAs, which will fail against the self-signed certificates most
+	// kubelets present; set CAFile to the cluster's CA, or Insecure for lab
+	// clusters where that verification isn't worth the setup.
+	DirectScrapeTLS transport.TLSConfig
+	// APIServerMetricsPath overrides the server-relative path the grabber
+	// requests for the apiserver's own /metrics endpoint. That endpoint is
+	// requested as an absolute path rather than through the usual
+	// Prefix/Resource request builder (it isn't namespaced under the
+	// versioned API), so it doesn't automatically pick up any path prefix
+	// a proxy in front of the apiserver rewrites requests with. Empty (the
+	// default) requests the unprefixed "/metrics".
+	APIServerMetricsPath string
+}
+
+// apiServerMetricsPath returns the path getMetricsFromApiServer should
+// request, applying MetricsGrabberOptions.APIServerMetricsPath if set.
+func (g *MetricsGrabber) apiServerMetricsPath() string {
+	if g.options.APIServerMetricsPath != "" {
+		return g.options.APIServerMetricsPath
+	}
+	return "/metrics"
+}
+
+// DefaultMetricsGrabberOptions returns the scrape timeouts a MetricsGrabber
+// uses unless overridden via NewMetricsGrabber's optional options argument.
+func DefaultMetricsGrabberOptions() MetricsGrabberOptions {
+	return MetricsGrabberOptions{
+		ApiServerTimeout:         10 * time.Second,
+		ControllerManagerTimeout: 10 * time.Second,
+		KubeletTimeout:           30 * time.Second,
+		SchedulerTimeout:         10 * time.Second,
+		DefaultTimeout:           10 * time.Second,
+		Parallelism:              8,
+		ScrapeRetries:            2,
+	}
+}
+
 type MetricsCollection struct {
 	ApiServerMetrics         ApiServerMetrics
 	ControllerManagerMetrics ControllerManagerMetrics
 	KubeletMetrics           map[string]KubeletMetrics
 	SchedulerMetrics         SchedulerMetrics
+	// Hollow is true when KubeletMetrics came from kubemark hollow-node
+	// pods rather than real kubelets, so scalability jobs can tag
+	// artifacts accordingly.
+	Hollow bool
+	// Health reports, for each component Grab attempted to scrape, whether
+	// it was reachable and why not when it wasn't, so a MetricsCollection
+	// with missing data is explainable from the artifact alone instead of
+	// requiring a re-run with more logging.
+	Health []ComponentHealth `json:"health,omitempty"`
+	// Flakes reports, for each component Grab scraped, how often the
+	// scrape needed a retry, so chronic flakiness on one path is
+	// quantified from the artifact instead of rediscovered by hand in
+	// every debugging session.
+	Flakes map[string]FlakeCounts `json:"flakes,omitempty"`
+	// ScrapeDurations reports how long Grab's scrape of each component
+	// took, so a slow metrics handler -- the kind that causes Prometheus
+	// scrape timeouts in production -- shows up in the artifact instead
+	// of going unmeasured.
+	ScrapeDurations map[string]time.Duration `json:"scrapeDurations,omitempty"`
+	// ScrapeTimings breaks ScrapeDurations down into connect/TTFB/body-read/
+	// parse phases for each component Grab successfully scraped, so a slow
+	// entry in ScrapeDurations is attributable to the proxy, the component,
+	// or this package's own parser instead of requiring a re-run with more
+	// logging to tell which.
+	ScrapeTimings map[string]ScrapePhaseTiming `json:"scrapeTimings,omitempty"`
+}
+
+// ComponentHealth records the outcome of Grab's attempt to scrape a single
+// component (or, for kubelets, the whole kubelet fleet).
+type ComponentHealth struct {
+	Component string `json:"component"`
+	Reachable bool   `json:"reachable"`
+	// Reason explains why Reachable is false -- not requested, not
+	// registered/configured, or the scrape error itself (which covers
+	// auth failures and unsupported-provider cases, since those surface as
+	// ordinary client errors). Empty when Reachable is true.
+	Reason string `json:"reason,omitempty"`
 }
 
+// ComponentKubelets is the Health component name Grab uses for the kubelet
+// fleet as a whole, since GrabFromAllKubelets reports on every node in one
+// call rather than one component at a time.
+const ComponentKubelets = "kubelets"
+
 type MetricsGrabber struct {
 	client                    *client.Client
+	metricsClient             *client.Client
+	portForwardConfig         *client.Config
+	options                   MetricsGrabberOptions
 	grabFromApiServer         bool
 	grabFromControllerManager bool
 	grabFromKubelets          bool
 	grabFromScheduler         bool
 	masterName                string
 	registeredMaster          bool
+	controlPlaneHealthy       bool
+	etcdHealthy               bool
+	kubemark                  bool
+	kubeletBearerToken        string
+
+	flakeMu sync.Mutex
+	flakes  map[string]*FlakeCounts
+
+	durationMu sync.Mutex
+	durations  map[string]time.Duration
+
+	phaseMu      sync.Mutex
+	phaseTimings map[string]ScrapePhaseTiming
+
+	kubeletLimitersMu sync.Mutex
+	kubeletLimiters   map[string]util.RateLimiter
+}
+
+// kubeletLimiter returns the per-node token-bucket rate limiter that bounds
+// scrapes of nodeName's kubelet, creating it on first use. Returns nil when
+// kubelet scraping isn't rate-limited (MetricsGrabberOptions.KubeletScrapeQPS
+// <= 0), so callers can skip straight through without an extra branch.
+func (g *MetricsGrabber) kubeletLimiter(nodeName string) util.RateLimiter {
+	if g.options.KubeletScrapeQPS <= 0 {
+		return nil
+	}
+	g.kubeletLimitersMu.Lock()
+	defer g.kubeletLimitersMu.Unlock()
+	if g.kubeletLimiters == nil {
+		g.kubeletLimiters = make(map[string]util.RateLimiter)
+	}
+	limiter, ok := g.kubeletLimiters[nodeName]
+	if !ok {
+		limiter = util.NewTokenBucketRateLimiter(g.options.KubeletScrapeQPS, g.options.KubeletScrapeBurst)
+		g.kubeletLimiters[nodeName] = limiter
+	}
+	return limiter
+}
+
+// withUserAgent sets req's User-Agent header to g.options.UserAgent, if one
+// is configured, and returns req unchanged otherwise, so every scrape
+// request builder can route through it without an explicit nil check at
+// each call site.
+func (g *MetricsGrabber) withUserAgent(req *client.Request) *client.Request {
+	if g.options.UserAgent != "" {
+		req.SetHeader("User-Agent", g.options.UserAgent)
+	}
+	return req
+}
+
+// FlakeCounts records, for one component, how often its scrapes needed a
+// retry, so a long-running suite can report chronic flakiness on a
+// specific path (e.g. the kubelet proxy) instead of it only surfacing as
+// isolated log noise in individual test failures.
+type FlakeCounts struct {
+	// Scrapes is how many scrapes of this component were attempted.
+	Scrapes int `json:"scrapes"`
+	// Retried is how many of those scrapes needed at least one retry
+	// before they succeeded (or failed for good).
+	Retried int `json:"retried"`
+	// Retries is the total number of retry attempts spent on this
+	// component, across all of its scrapes.
+	Retries int `json:"retries"`
+	// Failed is how many scrapes never succeeded, even after exhausting
+	// MetricsGrabberOptions.ScrapeRetries.
+	Failed int `json:"failed"`
+}
+
+// scrapeWithRetry calls scrape, retrying up to g.options.ScrapeRetries
+// times if it returns an error, and records the outcome in g's
+// FlakeReport under component. instance and path are used only to
+// attribute a failure in the returned *ScrapeError; pass "" for instance
+// on a singleton component like the apiserver. Safe to call concurrently,
+// e.g. from GrabFromAllKubelets's per-node goroutines.
+func (g *MetricsGrabber) scrapeWithRetry(component, instance, path string, scrape func() error) error {
+	var err error
+	attempts := 1
+	start := time.Now()
+	for ; ; attempts++ {
+		err = scrape()
+		if err == nil || attempts > g.options.ScrapeRetries {
+			break
+		}
+	}
+	duration := time.Since(start)
+
+	g.recordFlake(component, attempts-1, err != nil)
+	g.recordDuration(component, duration)
+
+	if err != nil {
+		return &ScrapeError{Component: component, Instance: instance, Path: path, Err: err}
+	}
+	if budget, ok := g.options.ScrapeDurationBudgets[component]; ok && duration > budget {
+		return &ScrapeError{Component: component, Instance: instance, Path: path,
+			Err: fmt.Errorf("took %v, exceeding its %v budget", duration, budget)}
+	}
+	return nil
+}
+
+// scrapePodWithRetry is scrapeWithRetry's counterpart for components whose
+// scrape target is resolved by discovery (pod lookup, leader lookup) rather
+// than fixed for the grabber's lifetime. resolve is re-run before every
+// attempt, so a retry following e.g. the scheduler pod getting rescheduled
+// mid-test targets whatever pod currently holds the role, instead of
+// retrying the now-gone pod name forever.
+func (g *MetricsGrabber) scrapePodWithRetry(component string, resolve func() (string, error), scrape func(instance string) (path string, err error)) error {
+	var err error
+	var instance, path string
+	attempts := 1
+	start := time.Now()
+	for ; ; attempts++ {
+		instance, err = resolve()
+		if err == nil {
+			path, err = scrape(instance)
+		}
+		if err == nil || attempts > g.options.ScrapeRetries {
+			break
+		}
+	}
+	duration := time.Since(start)
+
+	g.recordFlake(component, attempts-1, err != nil)
+	g.recordDuration(component, duration)
+
+	if err != nil {
+		return &ScrapeError{Component: component, Instance: instance, Path: path, Err: err}
+	}
+	if budget, ok := g.options.ScrapeDurationBudgets[component]; ok && duration > budget {
+		return &ScrapeError{Component: component, Instance: instance, Path: path,
+			Err: fmt.Errorf("took %v, exceeding its %v budget", duration, budget)}
+	}
+	return nil
+}
+
+func (g *MetricsGrabber) recordFlake(component string, retries int, failed bool) {
+	g.flakeMu.Lock()
+	defer g.flakeMu.Unlock()
+	if g.flakes == nil {
+		g.flakes = make(map[string]*FlakeCounts)
+	}
+	counts, ok := g.flakes[component]
+	if !ok {
+		counts = &FlakeCounts{}
+		g.flakes[component] = counts
+	}
+	counts.Scrapes++
+	counts.Retries += retries
+	if retries > 0 {
+		counts.Retried++
+	}
+	if failed {
+		counts.Failed++
+	}
+}
+
+// FlakeReport returns a copy of the grabber's per-component retry
+// accounting collected so far, keyed by the ComponentXxx constants (plus
+// ComponentKubelets). Components that haven't been scraped yet are
+// absent rather than zero-valued.
+func (g *MetricsGrabber) FlakeReport() map[string]FlakeCounts {
+	g.flakeMu.Lock()
+	defer g.flakeMu.Unlock()
+	result := make(map[string]FlakeCounts, len(g.flakes))
+	for component, counts := range g.flakes {
+		result[component] = *counts
+	}
+	return result
+}
+
+func (g *MetricsGrabber) recordDuration(component string, d time.Duration) {
+	g.durationMu.Lock()
+	defer g.durationMu.Unlock()
+	if g.durations == nil {
+		g.durations = make(map[string]time.Duration)
+	}
+	g.durations[component] = d
+}
+
+// GrabberStats bundles a component's FlakeCounts and most recent scrape
+// duration into one self-instrumentation snapshot. It describes the
+// MetricsGrabber's own behavior, not the value of anything it collected,
+// so a test that sees broken metric collection can tell whether the
+// problem is the cluster or the test harness itself.
+type GrabberStats struct {
+	FlakeCounts
+	// LastDuration is the most recent scrape's wall time, zero if the
+	// component hasn't been scraped yet.
+	LastDuration time.Duration
+	// LastTiming is the last successful scrape's phase breakdown, zero if
+	// the component hasn't been successfully scraped yet.
+	LastTiming ScrapePhaseTiming
+}
+
+// Stats returns the grabber's self-instrumentation for every component
+// it's scraped so far, keyed by the ComponentXxx constants (plus
+// ComponentKubelets).
+func (g *MetricsGrabber) Stats() map[string]GrabberStats {
+	flakes := g.FlakeReport()
+	durations := g.ScrapeDurations()
+	timings := g.ScrapePhaseTimings()
+	result := make(map[string]GrabberStats, len(flakes))
+	for component, counts := range flakes {
+		result[component] = GrabberStats{FlakeCounts: counts, LastDuration: durations[component], LastTiming: timings[component]}
+	}
+	return result
+}
+
+// ScrapeDurations returns a copy of the grabber's most recently observed
+// per-component scrape duration, keyed by the ComponentXxx constants (plus
+// ComponentKubelets). Each duration covers every attempt a retried scrape
+// needed, since that's the total time a caller actually waited on it.
+// Components that haven't been scraped yet are absent.
+func (g *MetricsGrabber) ScrapeDurations() map[string]time.Duration {
+	g.durationMu.Lock()
+	defer g.durationMu.Unlock()
+	result := make(map[string]time.Duration, len(g.durations))
+	for component, d := range g.durations {
+		result[component] = d
+	}
+	return result
+}
+
+// ScrapePhaseTiming breaks a single successful component scrape down into
+// the phases that can separately make it slow, so a slow scrape in CI can
+// be attributed to the network, the component, or this package's own
+// parser instead of only showing up as one opaque duration. Connect and
+// TTFB are only populated for scrape paths that issue requests with a raw
+// http.Client (currently the kubelet's direct and read-only strategies);
+// paths that go through the generic REST client (every other component,
+// and the kubelet's proxy strategy) don't expose connection-level timing,
+// so those leave Connect and BodyRead zero and attribute the whole round
+// trip to TTFB instead of misreporting it as zero.
+type ScrapePhaseTiming struct {
+	// Connect is how long establishing the connection took.
+	Connect time.Duration
+	// TTFB is how long it took for the first response byte to arrive once
+	// the request was sent -- a slow component, as opposed to a slow
+	// network or a slow parser, shows up here.
+	TTFB time.Duration
+	// BodyRead is how long reading and buffering the response body took,
+	// after the first byte arrived.
+	BodyRead time.Duration
+	// Parse is how long this package's own parseXMetrics took to decode
+	// the buffered body into a Metrics snapshot. A regression here points
+	// at our parser, not at the cluster.
+	Parse time.Duration
+}
+
+func (g *MetricsGrabber) recordPhaseTiming(component string, t ScrapePhaseTiming) {
+	g.phaseMu.Lock()
+	defer g.phaseMu.Unlock()
+	if g.phaseTimings == nil {
+		g.phaseTimings = make(map[string]ScrapePhaseTiming)
+	}
+	g.phaseTimings[component] = t
+}
+
+// ScrapePhaseTimings returns a copy of the grabber's most recently observed
+// per-component phase timing breakdown, keyed the same way as
+// ScrapeDurations. Only a scrape's last successful attempt updates it,
+// since a failed attempt's phases (a dial timeout, say) aren't
+// representative of what a healthy scrape costs. Components that haven't
+// been successfully scraped yet are absent.
+func (g *MetricsGrabber) ScrapePhaseTimings() map[string]ScrapePhaseTiming {
+	g.phaseMu.Lock()
+	defer g.phaseMu.Unlock()
+	result := make(map[string]ScrapePhaseTiming, len(g.phaseTimings))
+	for component, t := range g.phaseTimings {
+		result[component] = t
+	}
+	return result
 }
 
-// TODO: find a better way of figuring out if given node is a registered master.
+// masterRoleLabel is the node label kube-up sets on the master node.
+const masterRoleLabel = "kubernetes.io/role"
+
+// masterRoleValue is the value of masterRoleLabel on a master node.
+const masterRoleValue = "master"
+
 func isMasterNode(node *api.Node) bool {
+	if node.Labels[masterRoleLabel] == masterRoleValue {
+		return true
+	}
+	// Fall back to the legacy name-suffix heuristic for clusters whose
+	// nodes predate the role label.
 	return strings.HasSuffix(node.Name, "master")
 }
 
-func NewMetricsGrabber(c *client.Client, kubelets bool, scheduler bool, controllers bool, apiServer bool) (*MetricsGrabber, error) {
+// healthyComponentStatuses returns the names the apiserver's
+// componentstatuses list reports as healthy (e.g. "scheduler",
+// "controller-manager", "etcd-0"). This is the best discovery signal
+// available on clusters where the master isn't a registered Node at all
+// (e.g. GKE), so callers can tell which components exist and are worth
+// trying to reach through some other path, rather than an all-or-nothing
+// guess based on Node registration alone. Returns an empty set, rather than
+// an error, when componentstatuses can't be listed -- callers already treat
+// "nothing known to be healthy" as the conservative fallback.
+func healthyComponentStatuses(c *client.Client) sets.String {
+	healthy := sets.NewString()
+	statuses, err := c.ComponentStatuses().List(api.ListOptions{})
+	if err != nil {
+		return healthy
+	}
+	for _, status := range statuses.Items {
+		for _, condition := range status.Conditions {
+			if condition.Type == api.ComponentHealthy && condition.Status == api.ConditionTrue {
+				healthy.Insert(status.Name)
+			}
+		}
+	}
+	return healthy
+}
+
+// hasHealthyControlPlaneComponentStatuses reports whether the apiserver's
+// componentstatuses list shows both the scheduler and controller-manager as
+// healthy. Kept as the coarse signal behind MetricsGrabber.controlPlaneHealthy;
+// see healthyComponentStatuses for the finer-grained, per-component view
+// NewMetricsGrabber uses to decide which components it can individually
+// still reach.
+func hasHealthyControlPlaneComponentStatuses(c *client.Client) bool {
+	return healthyComponentStatuses(c).HasAll("scheduler", "controller-manager")
+}
+
+// hasHealthyEtcdComponentStatus reports whether healthy includes at least
+// one etcd instance (named "etcd-0", "etcd-1", etc. by the apiserver).
+func hasHealthyEtcdComponentStatus(healthy sets.String) bool {
+	for _, name := range healthy.List() {
+		if strings.HasPrefix(name, "etcd-") {
+			return true
+		}
+	}
+	return false
+}
+
+// NewMetricsGrabber builds a MetricsGrabber using DefaultMetricsGrabberOptions,
+// or the MetricsGrabberOptions passed in options[0] if provided.
+func NewMetricsGrabber(c *client.Client, kubelets bool, scheduler bool, controllers bool, apiServer bool, options ...MetricsGrabberOptions) (*MetricsGrabber, error) {
+	opts := DefaultMetricsGrabberOptions()
+	if len(options) > 0 {
+		opts = options[0]
+	}
+
 	registeredMaster := false
 	masterName := ""
 	nodeList, err := c.Nodes().List(api.ListOptions{})
@@ -68,23 +569,136 @@ func NewMetricsGrabber(c *client.Client, kubelets bool, scheduler bool, controll
 			break
 		}
 	}
+	healthyStatuses := sets.NewString()
 	if !registeredMaster {
+		healthyStatuses = healthyComponentStatuses(c)
+	}
+	controlPlaneHealthy := registeredMaster || healthyStatuses.HasAll("scheduler", "controller-manager")
+
+	// On a registered master, scraping goes through the node's pod proxy
+	// regardless of what componentstatuses reports, so these per-component
+	// checks only kick in for clusters like GKE where the master isn't a
+	// Node -- there, componentstatuses may show an unusual topology (e.g. a
+	// scheduler but no separate controller-manager) that a single combined
+	// on/off switch would get wrong.
+	if !registeredMaster && scheduler && !healthyStatuses.Has("scheduler") {
 		scheduler = false
+		glog.Warningf("Master node is not registered and componentstatuses doesn't report a healthy scheduler. Grabbing metrics from Scheduler is disabled.")
+	}
+	if !registeredMaster && controllers && !healthyStatuses.Has("controller-manager") {
 		controllers = false
-		glog.Warningf("Master node is not registered. Grabbing metrics from Scheduler and ControllerManager is disabled.")
+		glog.Warningf("Master node is not registered and componentstatuses doesn't report a healthy controller-manager. Grabbing metrics from ControllerManager is disabled.")
 	}
+	etcdHealthy := registeredMaster || hasHealthyEtcdComponentStatus(healthyStatuses)
 
 	return &MetricsGrabber{
 		client:                    c,
+		metricsClient:             c,
+		options:                   opts,
 		grabFromApiServer:         apiServer,
 		grabFromControllerManager: controllers,
 		grabFromKubelets:          kubelets,
 		grabFromScheduler:         scheduler,
 		masterName:                masterName,
 		registeredMaster:          registeredMaster,
+		controlPlaneHealthy:       controlPlaneHealthy,
+		etcdHealthy:               etcdHealthy,
 	}, nil
 }
 
+// NewLocalProxyMetricsGrabber builds a MetricsGrabber like NewMetricsGrabber,
+// but scrapes component metrics endpoints through a locally running
+// `kubectl proxy` listening at proxyAddress (e.g. "http://127.0.0.1:8001")
+// instead of constructing authenticated proxy requests with c. c is still
+// used to list nodes/pods to find what to scrape; only the raw metrics
+// fetch goes through the local proxy, which is useful against clusters
+// where only kubectl access -- not direct client credentials -- is
+// configured.
+func NewLocalProxyMetricsGrabber(c *client.Client, proxyAddress string, kubelets bool, scheduler bool, controllers bool, apiServer bool, options ...MetricsGrabberOptions) (*MetricsGrabber, error) {
+	grabber, err := NewMetricsGrabber(c, kubelets, scheduler, controllers, apiServer, options...)
+	if err != nil {
+		return nil, err
+	}
+	proxyClient, err := client.New(&client.Config{Host: proxyAddress})
+	if err != nil {
+		return nil, err
+	}
+	grabber.metricsClient = proxyClient
+	return grabber, nil
+}
+
+// NewPortForwardMetricsGrabber builds a MetricsGrabber like
+// NewMetricsGrabber, but scrapes pod-hosted components (scheduler,
+// controller-manager) through the pods/portforward subresource instead of
+// the pod proxy subresource. This is the only way to reach a self-hosted
+// control plane's metrics port when it isn't exposed via a Service or the
+// apiserver's pod proxy. config authenticates the port-forward's SPDY
+// upgrade request and is typically the same Config used to build c. Each
+// scrape opens and tears down its own tunnel; the grabber doesn't keep one
+// running between calls.
+func NewPortForwardMetricsGrabber(c *client.Client, config *client.Config, kubelets bool, scheduler bool, controllers bool, apiServer bool, options ...MetricsGrabberOptions) (*MetricsGrabber, error) {
+	grabber, err := NewMetricsGrabber(c, kubelets, scheduler, controllers, apiServer, options...)
+	if err != nil {
+		return nil, err
+	}
+	grabber.portForwardConfig = config
+	return grabber, nil
+}
+
+// NewMetricsGrabberFromContext builds a MetricsGrabber like
+// NewMetricsGrabber, but loads its client from a named context in the
+// kubeconfig at kubeconfigPath instead of an already-constructed client.
+// This lets a tool or test pick which of several contexts its scrapes take
+// -- e.g. an HA cluster's load-balanced endpoint vs. one individual master
+// -- the same way NewMultiClusterGrabber picks a context per federation
+// member, but for a single grabber. An empty context uses the kubeconfig's
+// current context.
+func NewMetricsGrabberFromContext(kubeconfigPath string, context string, kubelets bool, scheduler bool, controllers bool, apiServer bool, options ...MetricsGrabberOptions) (*MetricsGrabber, error) {
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfigPath},
+		&clientcmd.ConfigOverrides{CurrentContext: context}).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("building client config for context %q: %v", context, err)
+	}
+	c, err := client.New(config)
+	if err != nil {
+		return nil, fmt.Errorf("creating client for context %q: %v", context, err)
+	}
+	return NewMetricsGrabber(c, kubelets, scheduler, controllers, apiServer, options...)
+}
+
+// NewTokenAuthMetricsGrabber builds a MetricsGrabber like NewMetricsGrabber,
+// but scrapes kubelets by connecting to their secure port directly with
+// token, rather than through the apiserver's node proxy subresource. This is
+// the only way to reach kubelet metrics on providers that lock down the
+// proxy subresource (e.g. GKE, see SupportsKubeletProxyScrape), as long as
+// token carries authorization for the nodes/metrics resource. token is sent
+// as a bearer token on every kubelet scrape; it is not used for any other
+// component.
+func NewTokenAuthMetricsGrabber(c *client.Client, token string, kubelets bool, scheduler bool, controllers bool, apiServer bool, options ...MetricsGrabberOptions) (*MetricsGrabber, error) {
+	grabber, err := NewMetricsGrabber(c, kubelets, scheduler, controllers, apiServer, options...)
+	if err != nil {
+		return nil, err
+	}
+	grabber.kubeletBearerToken = token
+	return grabber, nil
+}
+
+// NewKubemarkMetricsGrabber builds a MetricsGrabber configured for a
+// kubemark scalability run, where "kubelets" are actually hollow-node pods
+// in an external cluster rather than real nodes. Kubelet scrapes are routed
+// through the pod proxy instead of the node proxy, and the resulting
+// MetricsCollection is tagged so the SLO machinery can tell the numbers came
+// from hollow nodes.
+func NewKubemarkMetricsGrabber(c *client.Client, kubelets bool, scheduler bool, controllers bool, apiServer bool, options ...MetricsGrabberOptions) (*MetricsGrabber, error) {
+	grabber, err := NewMetricsGrabber(c, kubelets, scheduler, controllers, apiServer, options...)
+	if err != nil {
+		return nil, err
+	}
+	grabber.kubemark = true
+	return grabber, nil
+}
+
 func (g *MetricsGrabber) GrabFromKubelet(nodeName string, unknownMetrics sets.String) (KubeletMetrics, error) {
 	nodes, err := g.client.Nodes().List(api.ListOptions{FieldSelector: fields.Set{client.ObjectNameField: nodeName}.AsSelector()})
 	if err != nil {
@@ -94,95 +708,780 @@ func (g *MetricsGrabber) GrabFromKubelet(nodeName string, unknownMetrics sets.St
 		return KubeletMetrics{}, fmt.Errorf("Error listing nodes with name %v, got %v", nodeName, nodes.Items)
 	}
 	kubeletPort := nodes.Items[0].Status.DaemonEndpoints.KubeletEndpoint.Port
-	return g.grabFromKubeletInternal(nodeName, kubeletPort, unknownMetrics)
+	return g.grabFromKubeletInternal(nodeName, kubeletPort, ExpectedKubeletMetrics(&nodes.Items[0]), unknownMetrics)
 }
 
-func (g *MetricsGrabber) grabFromKubeletInternal(nodeName string, kubeletPort int, unknownMetrics sets.String) (KubeletMetrics, error) {
+// kubeletScrapeStrategy is one path grabFromKubeletInternal can use to reach
+// a kubelet's /metrics endpoint.
+type kubeletScrapeStrategy struct {
+	name   string
+	scrape func() (string, ScrapePhaseTiming, error)
+}
+
+// kubeletScrapeStrategies returns, in the order grabFromKubeletInternal
+// should try them, the ways to reach nodeName's kubelet metrics. The
+// apiserver proxy is tried first since it needs no credentials beyond the
+// grabber's own, but some providers block it outright or flake on it under
+// load (#19468, see SupportsKubeletProxyScrape in test/e2e); a direct
+// scrape of the secure port is the next best thing, using whatever
+// g.kubeletBearerToken or MetricsGrabberOptions.DirectScrapeTLS the grabber
+// was configured with, and the unauthenticated read-only port is the last
+// resort on clusters that still expose it. Falling through the whole chain
+// lets a single grabber work across providers instead of every caller
+// having to know in advance which one will succeed.
+func (g *MetricsGrabber) kubeletScrapeStrategies(nodeName string, kubeletPort int, timeout time.Duration) []kubeletScrapeStrategy {
+	return []kubeletScrapeStrategy{
+		{"proxy", func() (string, ScrapePhaseTiming, error) {
+			return timedFetch(func() (string, error) { return g.getMetricsFromNodeProxy(nodeName, kubeletPort, timeout) })
+		}},
+		{"direct", func() (string, ScrapePhaseTiming, error) {
+			return g.getMetricsFromNodeDirect(nodeName, kubeletPort, timeout)
+		}},
+		{"readonly", func() (string, ScrapePhaseTiming, error) { return g.getMetricsFromNodeReadOnly(nodeName, timeout) }},
+	}
+}
+
+// grabFromKubeletInternal scrapes nodeName's kubelet and parses the result
+// against knownMetrics, which callers that already know the node's OS (e.g.
+// GrabFromAllKubelets) can set to ExpectedKubeletMetrics(node) instead of
+// the Linux-only KnownKubeletMetrics default.
+func (g *MetricsGrabber) grabFromKubeletInternal(nodeName string, kubeletPort int, knownMetrics map[string][]string, unknownMetrics sets.String) (KubeletMetrics, error) {
 	if kubeletPort <= 0 || kubeletPort > 65535 {
 		return KubeletMetrics{}, fmt.Errorf("Invalid Kubelet port %v. Skipping Kubelet's metrics gathering.", kubeletPort)
 	}
-	output, err := g.getMetricsFromNode(nodeName, kubeletPort)
+	if limiter := g.kubeletLimiter(nodeName); limiter != nil {
+		limiter.Accept()
+	}
+	var output string
+	var err error
+	var timing ScrapePhaseTiming
+	timingComponent := ComponentKubelets
+	if g.kubemark {
+		// Hollow nodes aren't reachable through the node proxy, or directly,
+		// since they're pods in an external cluster rather than real nodes.
+		path := fmt.Sprintf("pods/%v:%v/proxy/metrics", nodeName, kubeletPort)
+		err = g.scrapeWithRetry(ComponentKubelets, nodeName, path, func() error {
+			var fetchErr error
+			output, timing, fetchErr = timedFetch(func() (string, error) {
+				return g.getMetricsFromPod(nodeName, NamespaceKubemark, kubeletPort, g.options.KubeletTimeout)
+			})
+			return fetchErr
+		})
+	} else {
+		var errs []error
+		for _, strategy := range g.kubeletScrapeStrategies(nodeName, kubeletPort, g.options.KubeletTimeout) {
+			component := fmt.Sprintf("%v:%v", ComponentKubelets, strategy.name)
+			path := fmt.Sprintf("nodes/%v:%v/proxy/metrics", nodeName, kubeletPort)
+			err = g.scrapeWithRetry(component, nodeName, path, func() error {
+				var scrapeErr error
+				output, timing, scrapeErr = strategy.scrape()
+				return scrapeErr
+			})
+			if err == nil {
+				timingComponent = component
+				break
+			}
+			errs = append(errs, err)
+		}
+		if err != nil {
+			err = fmt.Errorf("all kubelet scrape strategies failed for node %v: %v", nodeName, errs)
+		}
+	}
 	if err != nil {
 		return KubeletMetrics{}, err
 	}
-	return parseKubeletMetrics(output, unknownMetrics)
+	if knownMetrics == nil {
+		knownMetrics = KnownKubeletMetrics
+	}
+	parseStart := time.Now()
+	result, err := parseKubeletMetricsWithSchema(output, knownMetrics, unknownMetrics)
+	timing.Parse = time.Since(parseStart)
+	g.recordPhaseTiming(timingComponent, timing)
+	return result, err
+}
+
+// grabFromKubeletEndpoint scrapes nodeName's kubelet at exactly kubeletPort
+// through the apiserver proxy (or directly, if g.kubeletBearerToken is
+// set), without falling through grabFromKubeletInternal's scrape strategy
+// chain. CompareKubeletEndpoints uses this to keep its two probes pinned to
+// the secure and read-only ports respectively, rather than risking both
+// comparisons quietly succeeding via the same fallback strategy.
+func (g *MetricsGrabber) grabFromKubeletEndpoint(nodeName string, kubeletPort int) (KubeletMetrics, error) {
+	var output string
+	var timing ScrapePhaseTiming
+	path := fmt.Sprintf("nodes/%v:%v/proxy/metrics", nodeName, kubeletPort)
+	err := g.scrapeWithRetry(ComponentKubelets, nodeName, path, func() error {
+		var err error
+		if g.kubeletBearerToken != "" {
+			output, timing, err = g.getMetricsFromNodeDirect(nodeName, kubeletPort, g.options.KubeletTimeout)
+		} else {
+			output, timing, err = timedFetch(func() (string, error) {
+				return g.getMetricsFromNodeProxy(nodeName, kubeletPort, g.options.KubeletTimeout)
+			})
+		}
+		return err
+	})
+	if err != nil {
+		return KubeletMetrics{}, err
+	}
+	parseStart := time.Now()
+	result, err := parseKubeletMetricsWithSchema(output, KnownKubeletMetrics, nil)
+	timing.Parse = time.Since(parseStart)
+	g.recordPhaseTiming(ComponentKubelets, timing)
+	return result, err
+}
+
+// CompareKubeletEndpoints scrapes nodeName's kubelet on both its secure
+// port (reported in the Node's status, typically 10250) and its insecure,
+// read-only port (ports.KubeletReadOnlyPort, 10255), and returns the
+// metric families present on only one of the two. Both ports are meant to
+// be views onto the same registry, so a non-empty result here points at
+// one of the handlers serving a stale or filtered registry rather than an
+// expected difference.
+func (g *MetricsGrabber) CompareKubeletEndpoints(nodeName string) (onlySecure, onlyInsecure []string, err error) {
+	nodes, err := g.client.Nodes().List(api.ListOptions{FieldSelector: fields.Set{client.ObjectNameField: nodeName}.AsSelector()})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(nodes.Items) != 1 {
+		return nil, nil, fmt.Errorf("Error listing nodes with name %v, got %v", nodeName, nodes.Items)
+	}
+	securePort := nodes.Items[0].Status.DaemonEndpoints.KubeletEndpoint.Port
+
+	secure, err := g.grabFromKubeletEndpoint(nodeName, securePort)
+	if err != nil {
+		return nil, nil, fmt.Errorf("scraping secure kubelet endpoint: %v", err)
+	}
+	insecure, err := g.grabFromKubeletEndpoint(nodeName, ports.KubeletReadOnlyPort)
+	if err != nil {
+		return nil, nil, fmt.Errorf("scraping insecure kubelet endpoint: %v", err)
+	}
+	onlySecure, onlyInsecure = CompareFamilySets(Metrics(secure), Metrics(insecure))
+	return onlySecure, onlyInsecure, nil
+}
+
+// findControlPlaneMirrorPod locates the mirror pod for a statically-deployed
+// control plane component in kube-system by its "component" label. This is
+// used on clusters where the master isn't a registered Node (e.g. GKE), so
+// its name can't be derived from a node name the way it can on GCE.
+func (g *MetricsGrabber) findControlPlaneMirrorPod(component string) (*api.Pod, error) {
+	pods, err := g.client.Pods(api.NamespaceSystem).List(api.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{"component": component}),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(pods.Items) == 0 {
+		return nil, fmt.Errorf("no %q pods found in namespace %q", component, api.NamespaceSystem)
+	}
+	return &pods.Items[0], nil
+}
+
+// leaderElectionHolder returns the HolderIdentity recorded in endpointName's
+// leader-election annotation in api.NamespaceSystem -- the hostname of
+// whichever instance currently holds the lock -- so callers can route a
+// scrape at the actual leader instead of guessing a pod name from node
+// naming conventions, which breaks down on multi-master clusters or once a
+// failover has moved the leader off the node that heuristic assumes.
+func (g *MetricsGrabber) leaderElectionHolder(endpointName string) (string, error) {
+	endpoint, err := g.client.Endpoints(api.NamespaceSystem).Get(endpointName)
+	if err != nil {
+		return "", err
+	}
+	recordJSON, ok := endpoint.Annotations[leaderelection.LeaderElectionRecordAnnotationKey]
+	if !ok {
+		return "", fmt.Errorf("endpoint %q has no %s annotation", endpointName, leaderelection.LeaderElectionRecordAnnotationKey)
+	}
+	var record leaderelection.LeaderElectionRecord
+	if err := json.Unmarshal([]byte(recordJSON), &record); err != nil {
+		return "", fmt.Errorf("parsing leader election record for endpoint %q: %v", endpointName, err)
+	}
+	if record.HolderIdentity == "" {
+		return "", fmt.Errorf("endpoint %q has no current leader", endpointName)
+	}
+	return record.HolderIdentity, nil
+}
+
+// schedulerPodName returns the name of the pod to scrape for scheduler
+// metrics. It prefers the current leader recorded in the "kube-scheduler"
+// leader-election endpoint; if that's unavailable (leader election
+// disabled, or the endpoint hasn't been created yet), it falls back to the
+// master-suffix heuristic on a registered master, and finally to a
+// kube-system mirror pod lookup.
+func (g *MetricsGrabber) schedulerPodName() (string, error) {
+	if holder, err := g.leaderElectionHolder("kube-scheduler"); err == nil {
+		return fmt.Sprintf("%v-%v", "kube-scheduler", holder), nil
+	}
+	if g.registeredMaster {
+		return fmt.Sprintf("%v-%v", "kube-scheduler", g.masterName), nil
+	}
+	pod, err := g.findControlPlaneMirrorPod("kube-scheduler")
+	if err != nil {
+		return "", err
+	}
+	return pod.Name, nil
+}
+
+// controllerManagerPodName returns the name of the pod to scrape for
+// controller-manager metrics, with the same leader-endpoint-first,
+// master-suffix-then-mirror-pod fallback chain as schedulerPodName.
+func (g *MetricsGrabber) controllerManagerPodName() (string, error) {
+	if holder, err := g.leaderElectionHolder("kube-controller-manager"); err == nil {
+		return fmt.Sprintf("%v-%v", "kube-controller-manager", holder), nil
+	}
+	if g.registeredMaster {
+		return fmt.Sprintf("%v-%v", "kube-controller-manager", g.masterName), nil
+	}
+	pod, err := g.findControlPlaneMirrorPod("kube-controller-manager")
+	if err != nil {
+		return "", err
+	}
+	return pod.Name, nil
+}
+
+// etcdPodName returns the name of the pod to scrape for etcd metrics,
+// falling back to a kube-system pod name-prefix lookup when the master
+// isn't a registered Node. Unlike the scheduler and controller-manager
+// manifests, etcd's static pod manifest doesn't set a "component" label, so
+// findControlPlaneMirrorPod's label selector can't find it.
+func (g *MetricsGrabber) etcdPodName() (string, error) {
+	if g.registeredMaster {
+		return fmt.Sprintf("%v-%v", "etcd-server", g.masterName), nil
+	}
+	pods, err := g.client.Pods(api.NamespaceSystem).List(api.ListOptions{})
+	if err != nil {
+		return "", err
+	}
+	for _, pod := range pods.Items {
+		if strings.HasPrefix(pod.Name, "etcd-server") {
+			return pod.Name, nil
+		}
+	}
+	return "", fmt.Errorf("no etcd-server pod found in namespace %q", api.NamespaceSystem)
+}
+
+// GrabFromEtcd scrapes etcd's /metrics endpoint through the node's pod
+// proxy subresource, the same way GrabFromScheduler reaches the scheduler,
+// so it works without SSH access on providers that register the master as
+// a Node.
+func (g *MetricsGrabber) GrabFromEtcd(unknownMetrics sets.String) (EtcdMetrics, error) {
+	if !g.registeredMaster && !g.etcdHealthy {
+		return EtcdMetrics{}, fmt.Errorf("Master's Kubelet is not registered, and componentstatuses doesn't report a healthy etcd. Skipping Etcd's metrics gathering.")
+	}
+	var output string
+	var timing ScrapePhaseTiming
+	err := g.scrapePodWithRetry(ComponentEtcd, g.etcdPodName, func(podName string) (string, error) {
+		path := fmt.Sprintf("pods/%v:%v/proxy/metrics", podName, EtcdClientPort)
+		var err error
+		output, timing, err = timedFetch(func() (string, error) {
+			return g.getMetricsFromPod(podName, api.NamespaceSystem, EtcdClientPort, g.options.DefaultTimeout)
+		})
+		return path, err
+	})
+	if err != nil {
+		return EtcdMetrics{}, err
+	}
+	parseStart := time.Now()
+	result, err := parseEtcdMetrics(output, unknownMetrics)
+	timing.Parse = time.Since(parseStart)
+	g.recordPhaseTiming(ComponentEtcd, timing)
+	return result, err
 }
 
 func (g *MetricsGrabber) GrabFromScheduler(unknownMetrics sets.String) (SchedulerMetrics, error) {
-	if !g.registeredMaster {
-		return SchedulerMetrics{}, fmt.Errorf("Master's Kubelet is not registered. Skipping Scheduler's metrics gathering.")
+	if !g.registeredMaster && !g.controlPlaneHealthy {
+		return SchedulerMetrics{}, fmt.Errorf("Master's Kubelet is not registered, and no healthy control plane componentstatuses were found. Skipping Scheduler's metrics gathering.")
 	}
-	output, err := g.getMetricsFromPod(fmt.Sprintf("%v-%v", "kube-scheduler", g.masterName), api.NamespaceSystem, ports.SchedulerPort)
+	var output string
+	var timing ScrapePhaseTiming
+	err := g.scrapePodWithRetry(ComponentScheduler, g.schedulerPodName, func(podName string) (string, error) {
+		path := fmt.Sprintf("pods/%v:%v/proxy/metrics", podName, ports.SchedulerPort)
+		var err error
+		output, timing, err = timedFetch(func() (string, error) {
+			return g.getMetricsFromPod(podName, api.NamespaceSystem, ports.SchedulerPort, g.options.SchedulerTimeout)
+		})
+		return path, err
+	})
 	if err != nil {
 		return SchedulerMetrics{}, err
 	}
-	return parseSchedulerMetrics(output, unknownMetrics)
+	parseStart := time.Now()
+	result, err := parseSchedulerMetrics(output, unknownMetrics)
+	timing.Parse = time.Since(parseStart)
+	g.recordPhaseTiming(ComponentScheduler, timing)
+	return result, err
 }
 
 func (g *MetricsGrabber) GrabFromControllerManager(unknownMetrics sets.String) (ControllerManagerMetrics, error) {
-	if !g.registeredMaster {
-		return ControllerManagerMetrics{}, fmt.Errorf("Master's Kubelet is not registered. Skipping ControllerManager's metrics gathering.")
+	if !g.registeredMaster && !g.controlPlaneHealthy {
+		return ControllerManagerMetrics{}, fmt.Errorf("Master's Kubelet is not registered, and no healthy control plane componentstatuses were found. Skipping ControllerManager's metrics gathering.")
 	}
-	output, err := g.getMetricsFromPod(fmt.Sprintf("%v-%v", "kube-controller-manager", g.masterName), api.NamespaceSystem, ports.ControllerManagerPort)
+	var output string
+	var timing ScrapePhaseTiming
+	err := g.scrapePodWithRetry(ComponentControllerManager, g.controllerManagerPodName, func(podName string) (string, error) {
+		path := fmt.Sprintf("pods/%v:%v/proxy/metrics", podName, ports.ControllerManagerPort)
+		var err error
+		output, timing, err = timedFetch(func() (string, error) {
+			return g.getMetricsFromPod(podName, api.NamespaceSystem, ports.ControllerManagerPort, g.options.ControllerManagerTimeout)
+		})
+		return path, err
+	})
 	if err != nil {
 		return ControllerManagerMetrics{}, err
 	}
-	return parseControllerManagerMetrics(output, unknownMetrics)
+	parseStart := time.Now()
+	result, err := parseControllerManagerMetrics(output, unknownMetrics)
+	timing.Parse = time.Since(parseStart)
+	g.recordPhaseTiming(ComponentControllerManager, timing)
+	return result, err
+}
+
+// timedFetch runs fetch and reports how long it took as a ScrapePhaseTiming
+// with only TTFB set, for scrape paths (anything going through the generic
+// REST client, i.e. every getMetricsFromX helper except the kubelet's
+// direct and read-only strategies) that don't have access to timedHTTPGet's
+// finer, connection-level breakdown. Attributing the whole round trip to
+// TTFB keeps it comparable to the component-was-slow signal timedHTTPGet's
+// TTFB reports, rather than silently dropping it from the snapshot.
+func timedFetch(fetch func() (string, error)) (string, ScrapePhaseTiming, error) {
+	start := time.Now()
+	output, err := fetch()
+	return output, ScrapePhaseTiming{TTFB: time.Since(start)}, err
+}
+
+// timedHTTPGet runs req with httpClient and returns its body alongside a
+// ScrapePhaseTiming covering the connect, TTFB and body-read phases (Parse
+// is left zero; callers fill it in once they've decoded the body). It's
+// used by the scrape paths that build their own *http.Request rather than
+// going through the generic REST client, where an httptrace.ClientTrace can
+// observe the phases directly.
+func timedHTTPGet(httpClient *http.Client, req *http.Request) (string, ScrapePhaseTiming, error) {
+	var timing ScrapePhaseTiming
+	var connectStart, requestSent time.Time
+	trace := &httptrace.ClientTrace{
+		ConnectStart: func(network, addr string) { connectStart = time.Now() },
+		ConnectDone: func(network, addr string, err error) {
+			if !connectStart.IsZero() {
+				timing.Connect += time.Since(connectStart)
+			}
+		},
+		WroteRequest: func(info httptrace.WroteRequestInfo) { requestSent = time.Now() },
+		GotFirstResponseByte: func() {
+			if !requestSent.IsZero() {
+				timing.TTFB = time.Since(requestSent)
+			}
+		},
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", timing, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", timing, fmt.Errorf("unexpected status %v", resp.Status)
+	}
+	readStart := time.Now()
+	body, err := ioutil.ReadAll(resp.Body)
+	timing.BodyRead = time.Since(readStart)
+	if err != nil {
+		return "", timing, err
+	}
+	return string(body), timing, nil
+}
+
+// getMetricsFromPodPortForward opens a port-forward tunnel to podName's
+// port, scrapes its /metrics endpoint through the tunnel, and tears the
+// tunnel down before returning, so no forwarding goroutine or listener
+// outlives a single scrape.
+func (g *MetricsGrabber) getMetricsFromPodPortForward(podName string, namespace string, port int, timeout time.Duration) (string, error) {
+	req := g.client.Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(podName).
+		SubResource("portforward")
+
+	dialer, err := remotecommand.NewExecutor(g.portForwardConfig, "POST", req.URL())
+	if err != nil {
+		return "", err
+	}
+
+	stopChan := make(chan struct{})
+	defer close(stopChan)
+
+	fw, err := portforward.New(dialer, []string{fmt.Sprintf("%d:%d", port, port)}, stopChan)
+	if err != nil {
+		return "", err
+	}
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- fw.ForwardPorts()
+	}()
+
+	select {
+	case <-fw.Ready:
+	case err := <-errChan:
+		return "", fmt.Errorf("port-forward to %v/%v:%v failed: %v", namespace, podName, port, err)
+	case <-time.After(readinessWaitTimeout):
+		return "", fmt.Errorf("timed out waiting for port-forward to %v/%v:%v", namespace, podName, port)
+	}
+
+	httpClient := &http.Client{Timeout: timeout}
+	resp, err := httpClient.Get(fmt.Sprintf("http://127.0.0.1:%d/metrics", port))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+// waitForApiServerReady waits until the apiserver's healthz endpoint reports
+// healthy, or readinessWaitTimeout elapses.
+func (g *MetricsGrabber) waitForApiServerReady() error {
+	return wait.Poll(readinessPollInterval, readinessWaitTimeout, func() (bool, error) {
+		body, err := g.metricsClient.Get().AbsPath("/healthz").DoRaw()
+		if err != nil {
+			return false, nil
+		}
+		return string(body) == "ok", nil
+	})
 }
 
 func (g *MetricsGrabber) GrabFromApiServer(unknownMetrics sets.String) (ApiServerMetrics, error) {
-	output, err := g.getMetricsFromApiServer()
+	if err := g.waitForApiServerReady(); err != nil {
+		return ApiServerMetrics{}, fmt.Errorf("waiting for apiserver to become ready: %v", err)
+	}
+	var output string
+	var timing ScrapePhaseTiming
+	err := g.scrapeWithRetry(ComponentApiServer, "", "/metrics", func() error {
+		var err error
+		output, timing, err = timedFetch(func() (string, error) {
+			return g.getMetricsFromApiServer(g.options.ApiServerTimeout)
+		})
+		return err
+	})
 	if err != nil {
-		return ApiServerMetrics{}, nil
+		return ApiServerMetrics{}, err
+	}
+	parseStart := time.Now()
+	result, err := parseApiServerMetrics(output, unknownMetrics)
+	timing.Parse = time.Since(parseStart)
+	g.recordPhaseTiming(ComponentApiServer, timing)
+	return result, err
+}
+
+// Component name constants for WaitForMetric.
+const (
+	ComponentApiServer         = "apiserver"
+	ComponentScheduler         = "scheduler"
+	ComponentControllerManager = "controller-manager"
+	ComponentEtcd              = "etcd"
+)
+
+// grabComponentMetrics grabs a fresh snapshot from the named control-plane
+// component, erasing its specific wrapper type so WaitForMetric can apply
+// the same matcher regardless of which component it's polling.
+func (g *MetricsGrabber) grabComponentMetrics(component string) (Metrics, error) {
+	switch component {
+	case ComponentApiServer:
+		m, err := g.GrabFromApiServer(nil)
+		return Metrics(m), err
+	case ComponentScheduler:
+		m, err := g.GrabFromScheduler(nil)
+		return Metrics(m), err
+	case ComponentControllerManager:
+		m, err := g.GrabFromControllerManager(nil)
+		return Metrics(m), err
+	default:
+		return nil, fmt.Errorf("WaitForMetric: unknown component %q", component)
+	}
+}
+
+// WaitForMetric polls component's metrics every readinessPollInterval until
+// matcher returns true for a freshly grabbed snapshot, or returns an error
+// once timeout elapses. It lets tests synchronize on a controller's
+// observable progress (e.g. a queue-length gauge returning to zero) instead
+// of sleeping for an arbitrary duration. A scrape failure is treated like a
+// non-match and retried rather than aborting the wait.
+func (g *MetricsGrabber) WaitForMetric(component string, matcher func(Metrics) bool, timeout time.Duration) error {
+	return wait.Poll(readinessPollInterval, timeout, func() (bool, error) {
+		current, err := g.grabComponentMetrics(component)
+		if err != nil {
+			return false, nil
+		}
+		return matcher(current), nil
+	})
+}
+
+// StabilityReport summarizes count repeated samples of a single metric
+// value, taken interval apart by MeasureStability, so a caller can check a
+// reading is settled before asserting a threshold against it -- a gauge
+// still bouncing between runs would otherwise make a pass or fail of that
+// threshold a coin flip rather than a meaningful result.
+type StabilityReport struct {
+	// Samples holds every value collected, in the order they were grabbed.
+	Samples []float64
+	// Mean is the arithmetic mean of Samples.
+	Mean float64
+	// StdDev is the population standard deviation of Samples.
+	StdDev float64
+}
+
+// MeasureStability grabs component's metrics count times, interval apart,
+// extracting one value from each snapshot with extract, and returns the
+// resulting StabilityReport. extract returning false is treated the same as
+// a scrape failure -- a metric that hasn't shown up yet (e.g. a counter
+// before any requests have incremented it) isn't trustworthy noise to
+// average in -- and aborts the measurement, returning the error alongside
+// whatever samples were already collected.
+func (g *MetricsGrabber) MeasureStability(component string, extract func(Metrics) (float64, bool), count int, interval time.Duration) (StabilityReport, error) {
+	var report StabilityReport
+	for i := 0; i < count; i++ {
+		if i > 0 {
+			time.Sleep(interval)
+		}
+		snapshot, err := g.grabComponentMetrics(component)
+		if err != nil {
+			return report, err
+		}
+		value, ok := extract(snapshot)
+		if !ok {
+			return report, fmt.Errorf("MeasureStability: metric not present in %v's snapshot %d/%d", component, i+1, count)
+		}
+		report.Samples = append(report.Samples, value)
 	}
-	return parseApiServerMetrics(output, unknownMetrics)
+	report.Mean, report.StdDev = MeanAndStdDev(report.Samples)
+	return report, nil
 }
 
+// Grab scrapes every component this grabber was configured for and returns
+// them together as a MetricsCollection, including a Health report of which
+// components were actually reachable. The error returned is non-nil if any
+// component failed, but result is still populated with whatever was
+// successfully grabbed (and the Health entries for what wasn't), so callers
+// that just log the error on failure don't also lose the rest of the data.
 func (g *MetricsGrabber) Grab(unknownMetrics sets.String) (MetricsCollection, error) {
-	result := MetricsCollection{}
+	result := MetricsCollection{Hollow: g.kubemark}
 	var errs []error
-	if g.grabFromApiServer {
-		metrics, err := g.GrabFromApiServer(nil)
-		if err != nil {
+
+	grabComponent := func(component string, requested bool, grab func() error) {
+		if !requested {
+			result.Health = append(result.Health, ComponentHealth{Component: component, Reachable: false, Reason: "not requested"})
+			return
+		}
+		if err := grab(); err != nil {
 			errs = append(errs, err)
-		} else {
-			result.ApiServerMetrics = metrics
+			result.Health = append(result.Health, ComponentHealth{Component: component, Reachable: false, Reason: err.Error()})
+			return
 		}
+		result.Health = append(result.Health, ComponentHealth{Component: component, Reachable: true})
 	}
-	if g.grabFromScheduler {
+
+	grabComponent(ComponentApiServer, g.grabFromApiServer, func() error {
+		metrics, err := g.GrabFromApiServer(nil)
+		if err == nil {
+			result.ApiServerMetrics = metrics
+		}
+		return err
+	})
+	grabComponent(ComponentScheduler, g.grabFromScheduler, func() error {
 		metrics, err := g.GrabFromScheduler(nil)
-		if err != nil {
-			errs = append(errs, err)
-		} else {
+		if err == nil {
 			result.SchedulerMetrics = metrics
 		}
-	}
-	if g.grabFromControllerManager {
+		return err
+	})
+	grabComponent(ComponentControllerManager, g.grabFromControllerManager, func() error {
 		metrics, err := g.GrabFromControllerManager(nil)
-		if err != nil {
-			errs = append(errs, err)
-		} else {
+		if err == nil {
 			result.ControllerManagerMetrics = metrics
 		}
+		return err
+	})
+	grabComponent(ComponentKubelets, g.grabFromKubelets, func() error {
+		metrics, err := g.GrabFromAllKubelets(nil)
+		result.KubeletMetrics = metrics
+		return err
+	})
+
+	result.Flakes = g.FlakeReport()
+	result.ScrapeDurations = g.ScrapeDurations()
+	result.ScrapeTimings = g.ScrapePhaseTimings()
+
+	if len(errs) > 0 {
+		return result, fmt.Errorf("Errors while grabbing metrics: %v", errs)
+	}
+	return result, nil
+}
+
+// ComponentClock is one component's process_start_time_seconds reading from
+// a MetricsCollection snapshot, converted to a time.Time.
+type ComponentClock struct {
+	Component string
+	StartTime time.Time
+}
+
+// ClockSkewReport names a pair of components whose process_start_time_seconds
+// readings, from the same MetricsCollection snapshot, differ by more than
+// the threshold DetectClockSkew was called with.
+type ClockSkewReport struct {
+	ComponentA string
+	ComponentB string
+	Skew       time.Duration
+}
+
+// componentClocks extracts every component's process_start_time_seconds
+// reading out of collection, skipping any component that wasn't
+// (successfully) scraped this round -- collection.Health already records
+// why, so DetectClockSkew doesn't need to duplicate that reporting.
+func componentClocks(collection MetricsCollection) []ComponentClock {
+	var clocks []ComponentClock
+	add := func(component string, m Metrics) {
+		if start, ok := latestValue(m["process_start_time_seconds"]); ok {
+			clocks = append(clocks, ComponentClock{Component: component, StartTime: time.Unix(int64(start), 0)})
+		}
 	}
-	if g.grabFromKubelets {
-		result.KubeletMetrics = make(map[string]KubeletMetrics)
-		nodes, err := g.client.Nodes().List(api.ListOptions{})
-		if err != nil {
-			errs = append(errs, err)
-		} else {
-			for _, node := range nodes.Items {
-				kubeletPort := node.Status.DaemonEndpoints.KubeletEndpoint.Port
-				metrics, err := g.grabFromKubeletInternal(node.Name, kubeletPort, nil)
-				if err != nil {
-					errs = append(errs, err)
-				}
-				result.KubeletMetrics[node.Name] = metrics
+	add(ComponentApiServer, Metrics(collection.ApiServerMetrics))
+	add(ComponentScheduler, Metrics(collection.SchedulerMetrics))
+	add(ComponentControllerManager, Metrics(collection.ControllerManagerMetrics))
+	for node, m := range collection.KubeletMetrics {
+		add(fmt.Sprintf("%v/%v", ComponentKubelets, node), Metrics(m))
+	}
+	return clocks
+}
+
+// DetectClockSkew compares every pair of components' process_start_time_seconds
+// readings in collection (a MetricsCollection from Grab) and reports the
+// pairs that disagree by more than threshold, since a component whose clock
+// has drifted corrupts any latency correlation drawn across the per-component
+// time-series artifacts a periodic Sampler produces, even though each
+// component's own measurements stay internally consistent.
+//
+// This is only meaningful once every component has been up for a while
+// after a common bring-up event (e.g. cluster creation) -- freshly started
+// components legitimately report very different process_start_time_seconds
+// from ones that have been running for hours, which this can't distinguish
+// from actual clock drift. Callers comparing a long-running cluster's
+// steady-state components are the intended use; comparing a
+// just-restarted component against the rest will produce false positives.
+func DetectClockSkew(collection MetricsCollection, threshold time.Duration) []ClockSkewReport {
+	clocks := componentClocks(collection)
+	var reports []ClockSkewReport
+	for i := 0; i < len(clocks); i++ {
+		for j := i + 1; j < len(clocks); j++ {
+			skew := clocks[i].StartTime.Sub(clocks[j].StartTime)
+			if skew < 0 {
+				skew = -skew
+			}
+			if skew > threshold {
+				reports = append(reports, ClockSkewReport{
+					ComponentA: clocks[i].Component,
+					ComponentB: clocks[j].Component,
+					Skew:       skew,
+				})
 			}
 		}
 	}
+	return reports
+}
+
+// SetParallelism overrides how many kubelets GrabFromAllKubelets scrapes
+// concurrently, in place of whatever MetricsGrabberOptions.Parallelism the
+// grabber was constructed with. n <= 0 is treated as 1, so callers can't
+// accidentally disable the bound entirely.
+func (g *MetricsGrabber) SetParallelism(n int) {
+	if n <= 0 {
+		n = 1
+	}
+	g.options.Parallelism = n
+}
+
+// SetKubeletScrapeRateLimit overrides how often a single kubelet's metrics
+// endpoint may be scraped, in place of whatever MetricsGrabberOptions.KubeletScrapeQPS
+// and KubeletScrapeBurst the grabber was constructed with. qps <= 0 disables
+// the limit, matching the MetricsGrabberOptions zero value. Any previously
+// created per-node limiters are discarded so the new rate takes effect
+// immediately.
+func (g *MetricsGrabber) SetKubeletScrapeRateLimit(qps float32, burst int) {
+	g.kubeletLimitersMu.Lock()
+	defer g.kubeletLimitersMu.Unlock()
+	g.options.KubeletScrapeQPS = qps
+	g.options.KubeletScrapeBurst = burst
+	g.kubeletLimiters = nil
+}
+
+// SetAPIServerMetricsPath overrides the server-relative path used to fetch
+// the apiserver's own /metrics, in place of whatever
+// MetricsGrabberOptions.APIServerMetricsPath the grabber was constructed
+// with. Empty restores the default unprefixed "/metrics".
+func (g *MetricsGrabber) SetAPIServerMetricsPath(path string) {
+	g.options.APIServerMetricsPath = path
+}
+
+// GrabFromAllKubelets scrapes every node's kubelet and returns its metrics
+// keyed by node name, so callers that need a per-node view (e.g. comparing
+// error counters across a test run) don't have to re-list nodes and grab
+// them one at a time. Up to g.options.Parallelism nodes are scraped at
+// once, since scraping a large cluster's kubelets one at a time can take
+// longer than the test budget allows. A per-node scrape failure is
+// collected and returned alongside whatever metrics were successfully
+// grabbed from the other nodes, rather than aborting the whole pass.
+func (g *MetricsGrabber) GrabFromAllKubelets(unknownMetrics sets.String) (map[string]KubeletMetrics, error) {
+	result := make(map[string]KubeletMetrics)
+	nodes, err := g.client.Nodes().List(api.ListOptions{})
+	if err != nil {
+		return result, err
+	}
+
+	parallelism := g.options.Parallelism
+	if parallelism <= 0 {
+		parallelism = 1
+	}
+
+	var (
+		mu        sync.Mutex
+		errs      []error
+		wg        sync.WaitGroup
+		semaphore = make(chan struct{}, parallelism)
+	)
+	for _, node := range nodes.Items {
+		wg.Add(1)
+		go func(node api.Node) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			// unknownMetrics is a sets.String, which isn't safe for
+			// concurrent mutation, so each goroutine collects into its
+			// own local set and merges it in under mu once it's done.
+			var localUnknown sets.String
+			if unknownMetrics != nil {
+				localUnknown = sets.NewString()
+			}
+			kubeletPort := node.Status.DaemonEndpoints.KubeletEndpoint.Port
+			metrics, err := g.grabFromKubeletInternal(node.Name, kubeletPort, ExpectedKubeletMetrics(&node), localUnknown)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, err)
+			}
+			result[node.Name] = metrics
+			if unknownMetrics != nil {
+				unknownMetrics.Insert(localUnknown.List()...)
+			}
+		}(node)
+	}
+	wg.Wait()
+
 	if len(errs) > 0 {
-		return MetricsCollection{}, fmt.Errorf("Errors while grabbing metrics: %v", errs)
+		return result, fmt.Errorf("Errors while grabbing metrics from kubelets: %v", errs)
 	}
 	return result, nil
 }
@@ -0,0 +1,70 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/util/sets"
+
+	"github.com/prometheus/common/model"
+)
+
+// KnownReschedulerMetrics covers the rescheduler addon's eviction/attempt
+// counters, so e2e tests can assert it actually acted rather than inferring
+// that from pod events alone.
+var KnownReschedulerMetrics = map[string][]string{
+	"rescheduler_eviction_attempts_total": {},
+	"rescheduler_evictions_total":         {},
+}
+
+type ReschedulerMetrics Metrics
+
+func (m *ReschedulerMetrics) Equal(o ReschedulerMetrics) bool {
+	return (*Metrics)(m).Equal(Metrics(o))
+}
+
+func NewReschedulerMetrics() ReschedulerMetrics {
+	result := NewMetrics()
+	for metric := range KnownReschedulerMetrics {
+		result[metric] = make(model.Samples, 0)
+	}
+	return ReschedulerMetrics(result)
+}
+
+func parseReschedulerMetrics(data string, unknownMetrics sets.String) (ReschedulerMetrics, error) {
+	result := NewReschedulerMetrics()
+	if err := parseMetrics(data, KnownReschedulerMetrics, (*Metrics)(&result), unknownMetrics); err != nil {
+		return ReschedulerMetrics{}, err
+	}
+	return result, nil
+}
+
+// GrabFromRescheduler scrapes metrics from the rescheduler addon's mirror
+// pod in kube-system, when it's deployed. Callers should treat a "not
+// found" error as "rescheduler isn't deployed on this cluster" rather than
+// a hard failure.
+func (g *MetricsGrabber) GrabFromRescheduler(unknownMetrics sets.String) (ReschedulerMetrics, error) {
+	pod, err := g.findControlPlaneMirrorPod("rescheduler")
+	if err != nil {
+		return ReschedulerMetrics{}, err
+	}
+	output, err := g.getMetricsFromPod(pod.Name, api.NamespaceSystem, 8080, g.options.DefaultTimeout)
+	if err != nil {
+		return ReschedulerMetrics{}, err
+	}
+	return parseReschedulerMetrics(output, unknownMetrics)
+}
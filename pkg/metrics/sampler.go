@@ -0,0 +1,78 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import "time"
+
+// Sample pairs a grabbed Metrics snapshot with the wall-clock time it was
+// taken, so callers computing a rate from two samples know the elapsed
+// window between them.
+type Sample struct {
+	Time    time.Time
+	Metrics Metrics
+}
+
+// Sampler periodically invokes a grab function and accumulates the results,
+// so load/scalability tests can compute throughput (e.g. pods scheduled per
+// second) over the course of a burst instead of only diffing start/end
+// snapshots.
+type Sampler struct {
+	interval time.Duration
+	grab     func() (Metrics, error)
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+	samples  []Sample
+}
+
+// NewSampler creates a Sampler that calls grab every interval once Start is
+// called.
+func NewSampler(interval time.Duration, grab func() (Metrics, error)) *Sampler {
+	return &Sampler{
+		interval: interval,
+		grab:     grab,
+		stopCh:   make(chan struct{}),
+		doneCh:   make(chan struct{}),
+	}
+}
+
+// Start begins sampling in the background.
+func (s *Sampler) Start() {
+	go func() {
+		defer close(s.doneCh)
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stopCh:
+				return
+			case t := <-ticker.C:
+				m, err := s.grab()
+				if err != nil {
+					continue
+				}
+				s.samples = append(s.samples, Sample{Time: t, Metrics: m})
+			}
+		}
+	}()
+}
+
+// Stop halts sampling and returns every sample collected, in order.
+func (s *Sampler) Stop() []Sample {
+	close(s.stopCh)
+	<-s.doneCh
+	return s.samples
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+// CapSamples trims every metric family in m down to at most maxPerFamily
+// samples, keeping the family key (with whatever samples survived) so
+// coverage checks can still see that the family was scraped at all. It
+// returns the number of samples dropped per family that was actually
+// capped, which is useful for accounting how much was thrown away when
+// grabbing high-cardinality metrics (e.g. per-container cAdvisor series on
+// a 110-pod node) without blowing up test-runner memory. A maxPerFamily of
+// 0 disables capping.
+func CapSamples(m Metrics, maxPerFamily int) map[string]int {
+	if maxPerFamily <= 0 {
+		return nil
+	}
+	var overflow map[string]int
+	for name, samples := range m {
+		if len(samples) <= maxPerFamily {
+			continue
+		}
+		if overflow == nil {
+			overflow = make(map[string]int)
+		}
+		overflow[name] = len(samples) - maxPerFamily
+		m[name] = samples[:maxPerFamily]
+	}
+	return overflow
+}
@@ -37,6 +37,20 @@ var KnownSchedulerMetrics = map[string][]string{
 	"scheduler_scheduling_algorithm_latency_microseconds":       {"quantile"},
 	"scheduler_scheduling_algorithm_latency_microseconds_count": {},
 	"scheduler_scheduling_algorithm_latency_microseconds_sum":   {},
+	"scheduler_predicate_evaluation_latency_microseconds":       {"predicate", "quantile"},
+	"scheduler_predicate_evaluation_latency_microseconds_count": {"predicate"},
+	"scheduler_predicate_evaluation_latency_microseconds_sum":   {"predicate"},
+	"scheduler_priority_evaluation_latency_microseconds":        {"priority", "quantile"},
+	"scheduler_priority_evaluation_latency_microseconds_count":  {"priority"},
+	"scheduler_priority_evaluation_latency_microseconds_sum":    {"priority"},
+}
+
+// KnownSchedulerMetricsByVersion resolves the known-metrics schema for a
+// specific scheduler release via ResolveKnownMetrics. It currently has only
+// a "default" entry; add a "major.minor" entry here once a release is found
+// to have actually added or removed scheduler metric families.
+var KnownSchedulerMetricsByVersion = KnownMetricsByVersion{
+	"default": KnownSchedulerMetrics,
 }
 
 type SchedulerMetrics Metrics
@@ -53,6 +67,33 @@ func NewSchedulerMetrics() SchedulerMetrics {
 	return SchedulerMetrics(result)
 }
 
+// SchedulingAlgorithmLatency returns the samples for the scheduling algorithm's
+// latency histogram, so callers don't need to know the raw metric name.
+func (m SchedulerMetrics) SchedulingAlgorithmLatency() model.Samples {
+	return m["scheduler_scheduling_algorithm_latency_microseconds"]
+}
+
+// SchedulingCount returns the samples for the end-to-end scheduling latency
+// summary's observation count, i.e. the number of pods scheduled, so
+// callers don't need to know the raw metric name to get at it.
+func (m SchedulerMetrics) SchedulingCount() model.Samples {
+	return m["scheduler_e2e_scheduling_latency_microseconds_count"]
+}
+
+// PredicateEvaluationLatency returns the samples for the per-predicate fit
+// evaluation latency, labeled by predicate name, so callers don't need to
+// know the raw metric name to get at it.
+func (m SchedulerMetrics) PredicateEvaluationLatency() model.Samples {
+	return m["scheduler_predicate_evaluation_latency_microseconds"]
+}
+
+// PriorityEvaluationLatency returns the samples for the per-priority-function
+// evaluation latency, labeled by priority name, so callers don't need to
+// know the raw metric name to get at it.
+func (m SchedulerMetrics) PriorityEvaluationLatency() model.Samples {
+	return m["scheduler_priority_evaluation_latency_microseconds"]
+}
+
 func parseSchedulerMetrics(data string, unknownMetrics sets.String) (SchedulerMetrics, error) {
 	result := NewSchedulerMetrics()
 	if err := parseMetrics(data, KnownSchedulerMetrics, (*Metrics)(&result), unknownMetrics); err != nil {
@@ -0,0 +1,44 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import "fmt"
+
+// ScrapeError wraps an error encountered while scraping a component's
+// metrics endpoint with the context needed to attribute it to a specific
+// component, instance and path, since a bare error surfacing from deep
+// inside a proxy call gives no indication of which of possibly dozens of
+// nodes or pods it came from.
+type ScrapeError struct {
+	// Component is one of the ComponentXxx constants (or ComponentKubelets).
+	Component string
+	// Instance names the specific pod or node the scrape targeted, or is
+	// empty for a singleton component reached directly (the apiserver).
+	Instance string
+	// Path is the URL path that was scraped, e.g. "/metrics" or a pod
+	// proxy subresource path.
+	Path string
+	// Err is the underlying error returned by the scrape.
+	Err error
+}
+
+func (e *ScrapeError) Error() string {
+	if e.Instance != "" {
+		return fmt.Sprintf("scraping %v %v at %v: %v", e.Component, e.Instance, e.Path, e.Err)
+	}
+	return fmt.Sprintf("scraping %v at %v: %v", e.Component, e.Path, e.Err)
+}
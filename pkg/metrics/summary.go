@@ -0,0 +1,72 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"github.com/prometheus/common/model"
+)
+
+// QuantileDrift describes how far a single summary quantile moved between
+// two snapshots of the same metric series.
+type QuantileDrift struct {
+	Metric   model.Metric
+	Baseline model.SampleValue
+	End      model.SampleValue
+}
+
+// Ratio returns how many times larger the end value is than the baseline
+// value. A baseline of zero is treated as drift whenever End is non-zero.
+func (d QuantileDrift) Ratio() float64 {
+	if d.Baseline == 0 {
+		if d.End == 0 {
+			return 1
+		}
+		return float64(d.End) + 1
+	}
+	return float64(d.End) / float64(d.Baseline)
+}
+
+// DetectQuantileDrift compares the quantiles reported by a summary-typed
+// metric's baseline and end-of-suite snapshots and returns the ones whose
+// value grew by at least minRatio (e.g. 2.0 flags anything that doubled).
+// This is the cheapest way to notice latency regressions in components that
+// only export summaries rather than histograms.
+func DetectQuantileDrift(baseline, end model.Samples, minRatio float64) []QuantileDrift {
+	baselineBySeries := make(map[model.Fingerprint]model.SampleValue, len(baseline))
+	for _, sample := range baseline {
+		if _, ok := sample.Metric[model.LabelName("quantile")]; !ok {
+			continue
+		}
+		baselineBySeries[sample.Metric.Fingerprint()] = sample.Value
+	}
+
+	var drifts []QuantileDrift
+	for _, sample := range end {
+		if _, ok := sample.Metric[model.LabelName("quantile")]; !ok {
+			continue
+		}
+		base, found := baselineBySeries[sample.Metric.Fingerprint()]
+		if !found {
+			continue
+		}
+		drift := QuantileDrift{Metric: sample.Metric, Baseline: base, End: sample.Value}
+		if drift.Ratio() >= minRatio {
+			drifts = append(drifts, drift)
+		}
+	}
+	return drifts
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/common/model"
+)
+
+func TestQuantileDriftRatio(t *testing.T) {
+	tests := []struct {
+		name string
+		d    QuantileDrift
+		want float64
+	}{
+		{"normal growth", QuantileDrift{Baseline: 1, End: 3}, 3},
+		{"shrink", QuantileDrift{Baseline: 4, End: 2}, 0.5},
+		{"baseline zero, end zero", QuantileDrift{Baseline: 0, End: 0}, 1},
+		{"baseline zero, end non-zero", QuantileDrift{Baseline: 0, End: 5}, 6},
+	}
+	for _, tc := range tests {
+		if got := tc.d.Ratio(); got != tc.want {
+			t.Errorf("%s: Ratio() = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func quantileSample(quantile string, value float64) *model.Sample {
+	return &model.Sample{
+		Metric: model.Metric{
+			model.MetricNameLabel: "test_latency",
+			"quantile":            model.LabelValue(quantile),
+		},
+		Value: model.SampleValue(value),
+	}
+}
+
+func TestDetectQuantileDrift(t *testing.T) {
+	baseline := model.Samples{
+		quantileSample("0.5", 0.1),
+		quantileSample("0.9", 0.2),
+	}
+	end := model.Samples{
+		quantileSample("0.5", 0.11), // within minRatio, not reported
+		quantileSample("0.9", 0.6),  // tripled, reported
+	}
+
+	drifts := DetectQuantileDrift(baseline, end, 2.0)
+	if len(drifts) != 1 {
+		t.Fatalf("got %d drifts, want 1: %+v", len(drifts), drifts)
+	}
+	if got := drifts[0].Metric["quantile"]; got != "0.9" {
+		t.Errorf("drifted quantile = %v, want 0.9", got)
+	}
+}
+
+func TestDetectQuantileDriftIgnoresNonSummarySamples(t *testing.T) {
+	baseline := model.Samples{{Metric: model.Metric{model.MetricNameLabel: "test_counter"}, Value: 1}}
+	end := model.Samples{{Metric: model.Metric{model.MetricNameLabel: "test_counter"}, Value: 100}}
+
+	if drifts := DetectQuantileDrift(baseline, end, 2.0); len(drifts) != 0 {
+		t.Errorf("got %d drifts for samples without a quantile label, want 0", len(drifts))
+	}
+}
+
+func TestDetectQuantileDriftNoBaselineMatch(t *testing.T) {
+	end := model.Samples{quantileSample("0.99", 1)}
+	if drifts := DetectQuantileDrift(nil, end, 2.0); len(drifts) != 0 {
+		t.Errorf("got %d drifts for a quantile with no baseline sample, want 0", len(drifts))
+	}
+}
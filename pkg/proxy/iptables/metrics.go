@@ -0,0 +1,53 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package iptables
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const kubeProxySubsystem = "kubeproxy"
+
+var (
+	syncProxyRulesFailuresTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Subsystem: kubeProxySubsystem,
+			Name:      "sync_proxy_rules_failure_total",
+			Help:      "Number of iptables-restore calls that failed while syncing proxy rules",
+		},
+	)
+	iptablesRulesTotal = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Subsystem: kubeProxySubsystem,
+			Name:      "iptables_rules_total",
+			Help:      "Number of iptables rules owned by kube-proxy as of the last successful sync",
+		},
+	)
+)
+
+var registerMetrics sync.Once
+
+// RegisterMetrics registers the iptables proxier's metrics. Callers must
+// invoke it once before the metrics can be scraped.
+func RegisterMetrics() {
+	registerMetrics.Do(func() {
+		prometheus.MustRegister(syncProxyRulesFailuresTotal)
+		prometheus.MustRegister(iptablesRulesTotal)
+	})
+}
@@ -180,6 +180,8 @@ func NewProxier(ipt utiliptables.Interface, exec utilexec.Interface, syncPeriod
 		glog.Warningf("can't set sysctl %s: %v", sysctlBridgeCallIptables, err)
 	}
 
+	RegisterMetrics()
+
 	return &Proxier{
 		serviceMap:    make(map[proxy.ServicePortName]*serviceInfo),
 		endpointsMap:  make(map[proxy.ServicePortName][]string),
@@ -763,12 +765,14 @@ func (proxier *Proxier) syncProxyRules() {
 	err = proxier.iptables.Restore(utiliptables.TableNAT, lines, utiliptables.NoFlushTables, utiliptables.RestoreCounters)
 	if err != nil {
 		glog.Errorf("Failed to sync iptables rules: %v", err)
+		syncProxyRulesFailuresTotal.Inc()
 		// Revert new local ports.
 		for k, v := range newLocalPorts {
 			glog.Errorf("Closing local port %s", k.String())
 			v.Close()
 		}
 	} else {
+		iptablesRulesTotal.Set(float64(bytes.Count(rulesLines.Bytes(), []byte("\n"))))
 		// Close old local ports and save new ones.
 		for k, v := range proxier.portsMap {
 			if newLocalPorts[k] == nil {
@@ -104,6 +104,14 @@ type Cacher struct {
 	stopped  bool
 	stopCh   chan struct{}
 	stopWg   sync.WaitGroup
+
+	// metrics records this Cacher's watch cache size, hit/miss counts and
+	// (re-)initialization duration, labeled by resourcePrefix.
+	metrics *cacherMetrics
+
+	// initStart is set just before each (re-)initialization from the
+	// underlying storage begins, so the onReplace handler can time it.
+	initStart time.Time
 }
 
 // Create a new Cacher responsible from service WATCH and LIST requests from its
@@ -159,12 +167,16 @@ func NewCacherFromConfig(config CacherConfig) *Cacher {
 		// - reflector.ListAndWatch
 		// and there are no guarantees on the order that they will stop.
 		// So we will be simply closing the channel, and synchronizing on the WaitGroup.
-		stopCh: make(chan struct{}),
-		stopWg: sync.WaitGroup{},
+		stopCh:  make(chan struct{}),
+		stopWg:  sync.WaitGroup{},
+		metrics: newCacherMetrics(config.ResourcePrefix),
 	}
 	cacher.usable.Lock()
 	// See startCaching method for why explanation on it.
-	watchCache.SetOnReplace(func() { cacher.usable.Unlock() })
+	watchCache.SetOnReplace(func() {
+		cacher.metrics.observeInitDuration(cacher.initStart)
+		cacher.usable.Unlock()
+	})
 	watchCache.SetOnEvent(cacher.processEvent)
 
 	stopCh := cacher.stopCh
@@ -197,6 +209,7 @@ func (c *Cacher) startCaching(stopChannel <-chan struct{}) {
 	// Note that since onReplace may be not called due to errors, we explicitly
 	// need to retry it on errors under lock.
 	for {
+		c.initStart = time.Now()
 		if err := c.reflector.ListAndWatch(stopChannel); err != nil {
 			glog.Errorf("unexpected ListAndWatch error: %v", err)
 		} else {
@@ -250,8 +263,12 @@ func (c *Cacher) Watch(ctx context.Context, key string, resourceVersion string,
 	defer c.watchCache.RUnlock()
 	initEvents, err := c.watchCache.GetAllEventsSinceThreadUnsafe(watchRV)
 	if err != nil {
+		// We couldn't serve the request from the watch cache window, so the
+		// caller will have to fall back to a full relist.
+		c.metrics.missCount.Inc()
 		return nil, err
 	}
+	c.metrics.hitCount.Inc()
 
 	c.Lock()
 	defer c.Unlock()
@@ -340,7 +357,11 @@ func (c *Cacher) Codec() runtime.Codec {
 	return c.storage.Codec()
 }
 
+// processEvent is invoked by watchCache as its onEvent handler, with
+// watchCache's lock already held, so it must use the ThreadUnsafe variants
+// of its accessors.
 func (c *Cacher) processEvent(event watchCacheEvent) {
+	c.metrics.size.Set(float64(c.watchCache.LenThreadUnsafe()))
 	c.Lock()
 	defer c.Unlock()
 	for _, watcher := range c.watchers {
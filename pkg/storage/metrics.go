@@ -0,0 +1,70 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// cacherMetrics exposes a Cacher's watch window size, hit/miss counts (did a
+// Watch() start from the in-memory window, or did it have to fall back to a
+// full relist because the requested resource version had already fallen out
+// of the window), and the time each (re-)initialization from the underlying
+// storage took, all labeled by the resource the Cacher is watching.
+type cacherMetrics struct {
+	size         prometheus.Gauge
+	hitCount     prometheus.Counter
+	missCount    prometheus.Counter
+	initDuration prometheus.Summary
+}
+
+func newCacherMetrics(resource string) *cacherMetrics {
+	constLabels := prometheus.Labels{"resource": resource}
+	m := &cacherMetrics{
+		size: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:        "watch_cache_size",
+			Help:        "Number of events currently held in the watch cache window, by resource",
+			ConstLabels: constLabels,
+		}),
+		hitCount: prometheus.NewCounter(prometheus.CounterOpts{
+			Name:        "watch_cache_hit_count",
+			Help:        "Number of Watch requests served from the watch cache window, by resource",
+			ConstLabels: constLabels,
+		}),
+		missCount: prometheus.NewCounter(prometheus.CounterOpts{
+			Name:        "watch_cache_miss_count",
+			Help:        "Number of Watch requests whose resource version had already fallen out of the watch cache window, by resource",
+			ConstLabels: constLabels,
+		}),
+		initDuration: prometheus.NewSummary(prometheus.SummaryOpts{
+			Name:        "watch_cache_init_duration_seconds",
+			Help:        "How long it took to (re-)initialize the watch cache from the underlying storage, by resource",
+			ConstLabels: constLabels,
+		}),
+	}
+	prometheus.Register(m.size)
+	prometheus.Register(m.hitCount)
+	prometheus.Register(m.missCount)
+	prometheus.Register(m.initDuration)
+	return m
+}
+
+func (m *cacherMetrics) observeInitDuration(start time.Time) {
+	m.initDuration.Observe(time.Since(start).Seconds())
+}
@@ -187,6 +187,13 @@ func (w *watchCache) updateCache(resourceVersion uint64, event watchCacheEvent)
 	w.endIndex++
 }
 
+// LenThreadUnsafe returns the number of events currently held in the cyclic
+// buffer. Callers must already hold w's lock, e.g. from within an onEvent
+// handler, which is invoked with it held.
+func (w *watchCache) LenThreadUnsafe() int {
+	return w.endIndex - w.startIndex
+}
+
 func (w *watchCache) List() []interface{} {
 	w.RLock()
 	defer w.RUnlock()
@@ -0,0 +1,128 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workqueue
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// queueMetrics is the instrumentation hook a Type calls into as items move
+// through it. noMetrics (used by New()) makes instrumentation opt-in, since
+// most workqueue-typed tests and one-off queues have no interest in
+// registering global Prometheus collectors.
+type queueMetrics interface {
+	add(item t)
+	get(item t)
+	done(item t)
+}
+
+type noMetrics struct{}
+
+func (noMetrics) add(item t)  {}
+func (noMetrics) get(item t)  {}
+func (noMetrics) done(item t) {}
+
+// defaultQueueMetrics exposes a named workqueue's depth, add rate, queue
+// latency (time between Add and Get), and per-item work duration (time
+// between Get and Done) as Prometheus metrics, so a stuck or backed-up
+// controller queue is visible without attaching a debugger.
+type defaultQueueMetrics struct {
+	depth        prometheus.Gauge
+	adds         prometheus.Counter
+	latency      prometheus.Summary
+	workDuration prometheus.Summary
+
+	mu              sync.Mutex
+	addTimes        map[t]time.Time
+	processingStart map[t]time.Time
+}
+
+func newDefaultQueueMetrics(name string) *defaultQueueMetrics {
+	constLabels := prometheus.Labels{"name": name}
+	m := &defaultQueueMetrics{
+		depth: prometheus.NewGauge(prometheus.GaugeOpts{
+			Subsystem:   "workqueue",
+			Name:        "depth",
+			Help:        "Current depth of a workqueue, by name",
+			ConstLabels: constLabels,
+		}),
+		adds: prometheus.NewCounter(prometheus.CounterOpts{
+			Subsystem:   "workqueue",
+			Name:        "adds",
+			Help:        "Total number of adds handled by a workqueue, by name",
+			ConstLabels: constLabels,
+		}),
+		latency: prometheus.NewSummary(prometheus.SummaryOpts{
+			Subsystem:   "workqueue",
+			Name:        "queue_latency_microseconds",
+			Help:        "How long an item stays in a workqueue before being requested, by name",
+			ConstLabels: constLabels,
+		}),
+		workDuration: prometheus.NewSummary(prometheus.SummaryOpts{
+			Subsystem:   "workqueue",
+			Name:        "work_duration_microseconds",
+			Help:        "How long processing an item from a workqueue takes, by name",
+			ConstLabels: constLabels,
+		}),
+		addTimes:        map[t]time.Time{},
+		processingStart: map[t]time.Time{},
+	}
+	prometheus.Register(m.depth)
+	prometheus.Register(m.adds)
+	prometheus.Register(m.latency)
+	prometheus.Register(m.workDuration)
+	return m
+}
+
+func (m *defaultQueueMetrics) add(item t) {
+	m.adds.Inc()
+	m.depth.Inc()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.addTimes[item]; !exists {
+		m.addTimes[item] = time.Now()
+	}
+}
+
+func (m *defaultQueueMetrics) get(item t) {
+	m.depth.Dec()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if start, ok := m.addTimes[item]; ok {
+		m.latency.Observe(microsecondsSince(start))
+		delete(m.addTimes, item)
+	}
+	m.processingStart[item] = time.Now()
+}
+
+func (m *defaultQueueMetrics) done(item t) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if start, ok := m.processingStart[item]; ok {
+		m.workDuration.Observe(microsecondsSince(start))
+		delete(m.processingStart, item)
+	}
+}
+
+func microsecondsSince(start time.Time) float64 {
+	return float64(time.Since(start).Nanoseconds()) / float64(time.Microsecond)
+}
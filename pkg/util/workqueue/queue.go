@@ -20,12 +20,26 @@ import (
 	"sync"
 )
 
-// New constructs a new workqueue (see the package comment).
+// New constructs a new workqueue (see the package comment). It is not
+// registered as a Prometheus metrics source; use NewNamed for that.
 func New() *Type {
+	return newQueue(noMetrics{})
+}
+
+// NewNamed constructs a new workqueue whose depth, add rate, queue latency,
+// and per-item work duration are registered as Prometheus metrics under
+// name, so a stuck or backed-up controller queue shows up without attaching
+// a debugger.
+func NewNamed(name string) *Type {
+	return newQueue(newDefaultQueueMetrics(name))
+}
+
+func newQueue(metrics queueMetrics) *Type {
 	return &Type{
 		dirty:      set{},
 		processing: set{},
 		cond:       sync.NewCond(&sync.Mutex{}),
+		metrics:    metrics,
 	}
 }
 
@@ -48,6 +62,8 @@ type Type struct {
 	cond *sync.Cond
 
 	shuttingDown bool
+
+	metrics queueMetrics
 }
 
 type empty struct{}
@@ -82,6 +98,7 @@ func (q *Type) Add(item interface{}) {
 		return
 	}
 	q.queue = append(q.queue, item)
+	q.metrics.add(item)
 	q.cond.Signal()
 }
 
@@ -108,6 +125,7 @@ func (q *Type) Get() (item interface{}, shutdown bool) {
 		return nil, true
 	}
 	item, q.queue = q.queue[0], q.queue[1:]
+	q.metrics.get(item)
 	q.processing.insert(item)
 	q.dirty.delete(item)
 	return item, false
@@ -119,9 +137,11 @@ func (q *Type) Get() (item interface{}, shutdown bool) {
 func (q *Type) Done(item interface{}) {
 	q.cond.L.Lock()
 	defer q.cond.L.Unlock()
+	q.metrics.done(item)
 	q.processing.delete(item)
 	if q.dirty.has(item) {
 		q.queue = append(q.queue, item)
+		q.metrics.add(item)
 		q.cond.Signal()
 	}
 }
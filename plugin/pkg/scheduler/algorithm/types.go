@@ -27,6 +27,7 @@ type FitPredicate func(pod *api.Pod, existingPods []*api.Pod, node string) (bool
 type PriorityFunction func(pod *api.Pod, machineToPods map[string][]*api.Pod, podLister PodLister, nodeLister NodeLister) (schedulerapi.HostPriorityList, error)
 
 type PriorityConfig struct {
+	Name     string
 	Function PriorityFunction
 	Weight   int
 }
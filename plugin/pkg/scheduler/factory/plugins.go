@@ -262,6 +262,7 @@ func getPriorityFunctionConfigs(names sets.String, args PluginFactoryArgs) ([]al
 			return nil, fmt.Errorf("Invalid priority name %s specified - no corresponding function found", name)
 		}
 		configs = append(configs, algorithm.PriorityConfig{
+			Name:     name,
 			Function: factory.Function(args),
 			Weight:   factory.Weight,
 		})
@@ -22,6 +22,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/golang/glog"
 	"k8s.io/kubernetes/pkg/api"
@@ -30,6 +31,7 @@ import (
 	"k8s.io/kubernetes/plugin/pkg/scheduler/algorithm"
 	"k8s.io/kubernetes/plugin/pkg/scheduler/algorithm/predicates"
 	schedulerapi "k8s.io/kubernetes/plugin/pkg/scheduler/api"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/metrics"
 )
 
 type FailedPredicateMap map[string]sets.String
@@ -128,7 +130,9 @@ func findNodesThatFit(pod *api.Pod, machineToPods map[string][]*api.Pod, predica
 		fits := true
 		for name, predicate := range predicateFuncs {
 			predicates.FailedResourceType = ""
+			predicateStart := time.Now()
 			fit, err := predicate(pod, machineToPods[node.Name], node.Name)
+			metrics.PredicateEvaluationLatency.WithLabelValues(name).Observe(metrics.SinceInMicroseconds(predicateStart))
 			if err != nil {
 				return api.NodeList{}, FailedPredicateMap{}, err
 			}
@@ -197,7 +201,9 @@ func PrioritizeNodes(pod *api.Pod, machinesToPods map[string][]*api.Pod, podList
 			defer wg.Done()
 			weight := config.Weight
 			priorityFunc := config.Function
+			priorityStart := time.Now()
 			prioritizedList, err := priorityFunc(pod, machinesToPods, podLister, nodeLister)
+			metrics.PriorityEvaluationLatency.WithLabelValues(config.Name).Observe(metrics.SinceInMicroseconds(priorityStart))
 			if err != nil {
 				mu.Lock()
 				errs = append(errs, err)
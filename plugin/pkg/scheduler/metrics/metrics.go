@@ -59,6 +59,24 @@ var (
 			Help:      "Binding rateLimiter's saturation rate in percentage",
 		},
 	)
+	PredicateEvaluationLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: schedulerSubsystem,
+			Name:      "predicate_evaluation_latency_microseconds",
+			Help:      "Latency for evaluating a single predicate function against a single node",
+			Buckets:   prometheus.ExponentialBuckets(1000, 2, 15),
+		},
+		[]string{"predicate"},
+	)
+	PriorityEvaluationLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: schedulerSubsystem,
+			Name:      "priority_evaluation_latency_microseconds",
+			Help:      "Latency for evaluating a single priority function across all filtered nodes",
+			Buckets:   prometheus.ExponentialBuckets(1000, 2, 15),
+		},
+		[]string{"priority"},
+	)
 )
 
 var registerMetrics sync.Once
@@ -71,6 +89,8 @@ func Register() {
 		prometheus.MustRegister(SchedulingAlgorithmLatency)
 		prometheus.MustRegister(BindingLatency)
 		prometheus.MustRegister(BindingRateLimiterSaturation)
+		prometheus.MustRegister(PredicateEvaluationLatency)
+		prometheus.MustRegister(PriorityEvaluationLatency)
 	})
 }
 
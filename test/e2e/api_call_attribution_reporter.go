@@ -0,0 +1,106 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"strings"
+
+	"github.com/onsi/ginkgo/config"
+	"github.com/onsi/ginkgo/types"
+	"github.com/prometheus/common/model"
+
+	client "k8s.io/kubernetes/pkg/client/unversioned"
+	"k8s.io/kubernetes/pkg/metrics"
+)
+
+// apiCallAttributionUserAgent identifies this reporter's own apiserver
+// scrapes via MetricsGrabberOptions.UserAgent, so they're excluded from the
+// totals it attributes to specs -- without this, every spec's count would
+// include noise from the reporter measuring it.
+const apiCallAttributionUserAgent = "e2e-api-call-attribution-reporter"
+
+// APICallAttributionReporter is a Ginkgo reporter that snapshots the
+// apiserver's total request count before and after each spec and records
+// the delta, so a spec that makes an unexpectedly large number of API
+// calls -- e.g. from an accidental poll loop -- shows up in the suite's own
+// output instead of requiring a separate profiling run to notice.
+type APICallAttributionReporter struct {
+	client  *client.Client
+	grabber *metrics.MetricsGrabber
+	before  float64
+	result  metrics.Metrics
+}
+
+// NewAPICallAttributionReporter creates an APICallAttributionReporter ready
+// to be passed to ginkgo.RunSpecsWithDefaultAndCustomReporters.
+func NewAPICallAttributionReporter(c *client.Client) *APICallAttributionReporter {
+	return &APICallAttributionReporter{client: c, result: make(metrics.Metrics)}
+}
+
+func (r *APICallAttributionReporter) SpecSuiteWillBegin(config config.GinkgoConfigType, summary *types.SuiteSummary) {
+	grabber, err := metrics.NewMetricsGrabber(r.client, false, false, false, true, metrics.MetricsGrabberOptions{
+		UserAgent: apiCallAttributionUserAgent,
+	})
+	if err != nil {
+		logGrabFailure("setting up API-call attribution reporting", err)
+		return
+	}
+	r.grabber = grabber
+}
+
+func (r *APICallAttributionReporter) BeforeSuiteDidRun(setupSummary *types.SetupSummary) {}
+
+func (r *APICallAttributionReporter) SpecWillRun(specSummary *types.SpecSummary) {
+	r.before = r.totalRequests()
+}
+
+func (r *APICallAttributionReporter) SpecDidComplete(specSummary *types.SpecSummary) {
+	delta := r.totalRequests() - r.before
+	if delta < 0 {
+		// apiserver_request_count can be reset independently of this
+		// reporter's own lifetime (e.g. by pkg/apiserver/metrics.Reset);
+		// treat a negative delta as unknown rather than misreport it.
+		return
+	}
+	r.result["e2e_test_api_calls_total"] = append(r.result["e2e_test_api_calls_total"], &model.Sample{
+		Metric: model.Metric{"name": model.LabelValue(strings.Join(specSummary.ComponentTexts[1:], " "))},
+		Value:  model.SampleValue(delta),
+	})
+}
+
+func (r *APICallAttributionReporter) AfterSuiteDidRun(setupSummary *types.SetupSummary) {}
+
+func (r *APICallAttributionReporter) SpecSuiteDidEnd(summary *types.SuiteSummary) {
+	Logf("Per-test API call attribution (this reporter's own scrape traffic excluded):\n%s", r.result.String())
+}
+
+// totalRequests returns the apiserver's total request count so far, with
+// this reporter's own scrape requests subtracted out via
+// metrics.RequestCountExcludingUserAgent, so the reporter measuring the
+// count doesn't inflate it.
+func (r *APICallAttributionReporter) totalRequests() float64 {
+	if r.grabber == nil {
+		return 0
+	}
+	response, err := r.grabber.GrabFromApiServer(nil)
+	if err != nil {
+		logGrabFailure("checking API-call attribution", err)
+		return 0
+	}
+	counted := metrics.RequestCountExcludingUserAgent(response.RequestCount(), apiCallAttributionUserAgent)
+	return metrics.SumValues(counted)
+}
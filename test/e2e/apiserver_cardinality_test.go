@@ -0,0 +1,79 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"fmt"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/metrics"
+	"k8s.io/kubernetes/pkg/util/sets"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// numCardinalityProbeObjects is large enough that a label accidentally keyed
+// on object name (instead of a fixed, small set of values like verb or
+// resource) would stand out as a cardinality outlier, but small enough to
+// keep the test fast.
+const numCardinalityProbeObjects = 50
+
+var _ = Describe("Apiserver metrics cardinality", func() {
+	framework := NewFramework("apiserver-metrics-cardinality")
+
+	It("should not record object names as apiserver request metric label values", func() {
+		names := sets.NewString()
+		for i := 0; i < numCardinalityProbeObjects; i++ {
+			name := fmt.Sprintf("cardinality-probe-%d", i)
+			names.Insert(name)
+			_, err := framework.Client.Extensions().ConfigMaps(framework.Namespace.Name).Create(&api.ConfigMap{
+				ObjectMeta: api.ObjectMeta{Name: name},
+			})
+			expectNoError(err)
+		}
+
+		grabber, err := metrics.NewMetricsGrabber(framework.Client, false, false, false, true)
+		expectNoError(err)
+		current, err := grabber.GrabFromApiServer(nil)
+		expectNoError(err)
+
+		requestCount := current.RequestCount()
+		Expect(requestCount).NotTo(BeEmpty(), "expected apiserver_request_count to have samples after creating configmaps")
+
+		leaked := sets.NewString()
+		distinctValuesPerLabel := make(map[string]sets.String)
+		for _, sample := range requestCount {
+			for label, value := range sample.Metric {
+				if names.Has(string(value)) {
+					leaked.Insert(string(value))
+				}
+				if distinctValuesPerLabel[string(label)] == nil {
+					distinctValuesPerLabel[string(label)] = sets.NewString()
+				}
+				distinctValuesPerLabel[string(label)].Insert(string(value))
+			}
+		}
+		Expect(leaked.List()).To(BeEmpty(), "apiserver_request_count label values included created object names, indicating a cardinality regression")
+
+		for label, values := range distinctValuesPerLabel {
+			Expect(values.Len()).To(BeNumerically("<", numCardinalityProbeObjects),
+				"label %q on apiserver_request_count has %d distinct values after creating %d objects -- it looks like it's keyed on an unbounded value such as object name",
+				label, values.Len(), numCardinalityProbeObjects)
+		}
+	})
+})
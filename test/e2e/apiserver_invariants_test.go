@@ -0,0 +1,90 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"k8s.io/kubernetes/pkg/metrics"
+	"k8s.io/kubernetes/pkg/util/sets"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// verbsAllowedToDropRequests lists verbs known to legitimately hit the
+// apiserver's max-in-flight limiter during a heavy e2e run (e.g. watches
+// re-established in bulk), so the invariant below doesn't flake on them.
+var verbsAllowedToDropRequests = sets.NewString("WATCH", "WATCHLIST")
+
+var _ = Describe("Apiserver metrics invariants", func() {
+	framework := NewFramework("apiserver-invariants")
+
+	It("should not observe any new apiserver panics during the test run", func() {
+		if !testContext.GatherMetricsBeforeTest || framework.BaselineMetrics == nil {
+			Skipf("Requires --gather-metrics-at-setup to have a baseline to diff against.")
+		}
+		baseline := metrics.SumValues(framework.BaselineMetrics.ApiServerMetrics.PanicCount())
+
+		grabber, err := metrics.NewMetricsGrabber(framework.Client, false, false, false, true)
+		expectNoError(err)
+		current, err := grabber.GrabFromApiServer(nil)
+		expectNoError(err)
+
+		end := metrics.SumValues(current.PanicCount())
+		Expect(end).To(Equal(baseline), "apiserver_panic_count increased during the test run (from %v to %v)", baseline, end)
+	})
+
+	It("should not leak objects in etcd across the test run", func() {
+		if !testContext.GatherMetricsBeforeTest || framework.BaselineMetrics == nil {
+			Skipf("Requires --gather-metrics-at-setup to have a baseline to diff against.")
+		}
+		baseline := metrics.ValuesByLabel(framework.BaselineMetrics.ApiServerMetrics.ObjectCounts(), "resource")
+
+		grabber, err := metrics.NewMetricsGrabber(framework.Client, false, false, false, true)
+		expectNoError(err)
+		current, err := grabber.GrabFromApiServer(nil)
+		expectNoError(err)
+		end := metrics.ValuesByLabel(current.ObjectCounts(), "resource")
+
+		grown := make(map[string]float64)
+		for resource, count := range end {
+			if count > baseline[resource] {
+				grown[resource] = count - baseline[resource]
+			}
+		}
+		Expect(grown).To(BeEmpty(), "object counts grew across the test run: %v", grown)
+	})
+
+	It("should not drop or reject requests during the test run", func() {
+		if !testContext.GatherMetricsBeforeTest || framework.BaselineMetrics == nil {
+			Skipf("Requires --gather-metrics-at-setup to have a baseline to diff against.")
+		}
+		grabber, err := metrics.NewMetricsGrabber(framework.Client, false, false, false, true)
+		expectNoError(err)
+		current, err := grabber.GrabFromApiServer(nil)
+		expectNoError(err)
+
+		baseline := metrics.Metrics(framework.BaselineMetrics.ApiServerMetrics)
+		phase := metrics.ApiServerMetrics(metrics.Metrics(current).SubtractBaseline(baseline))
+		grown := metrics.ValuesByLabel(phase.DroppedRequestsCount(), "verb")
+		for verb := range grown {
+			if verbsAllowedToDropRequests.Has(verb) || grown[verb] <= 0 {
+				delete(grown, verb)
+			}
+		}
+		Expect(grown).To(BeEmpty(), "apiserver dropped/rejected requests during the test run: %v", grown)
+	})
+})
@@ -0,0 +1,89 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"k8s.io/kubernetes/pkg/metrics"
+	"k8s.io/kubernetes/pkg/util/sets"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/common/model"
+)
+
+// cadvisorMachineMetrics and cadvisorContainerMetrics are the cAdvisor
+// metric families dashboards are built directly on top of, so this suite
+// checks their names and label sets didn't drift rather than letting a
+// kubelet/cAdvisor upgrade surface as a silently broken dashboard.
+var cadvisorMachineMetrics = []string{
+	"machine_cpu_cores",
+	"machine_memory_bytes",
+}
+
+var cadvisorContainerMetrics = []string{
+	"container_cpu_usage_seconds_total",
+	"container_memory_usage_bytes",
+	"container_memory_working_set_bytes",
+	"container_fs_usage_bytes",
+	"container_network_receive_bytes_total",
+	"container_network_transmit_bytes_total",
+	"container_spec_cpu_shares",
+	"container_last_seen",
+}
+
+var _ = Describe("Cadvisor metrics", func() {
+	framework := NewFramework("cadvisor-metrics")
+
+	It("should expose stable machine_ and container_ metric families with bounded label sets", func() {
+		nodes := ListSchedulableNodesOrDie(framework.Client)
+		Expect(nodes.Items).NotTo(BeEmpty(), "expected at least one schedulable node")
+
+		grabber, err := metrics.NewMetricsGrabber(framework.Client, true, false, false, false)
+		expectNoError(err)
+		kubeletMetrics, err := grabber.GrabFromKubelet(nodes.Items[0].Name, nil)
+		expectNoError(err)
+
+		for _, name := range cadvisorMachineMetrics {
+			assertKnownCadvisorFamily(kubeletMetrics, name)
+		}
+		for _, name := range cadvisorContainerMetrics {
+			assertKnownCadvisorFamily(kubeletMetrics, name)
+		}
+	})
+})
+
+// assertKnownCadvisorFamily fails the test unless metric is declared in
+// metrics.KnownKubeletMetrics, has at least one sample in snapshot, and
+// every sample's labels are a subset of the declared label set -- an
+// unannounced new label is exactly the kind of silent schema drift that
+// breaks dashboards built on these families.
+func assertKnownCadvisorFamily(snapshot metrics.KubeletMetrics, metric string) {
+	allowed, ok := metrics.KnownKubeletMetrics[metric]
+	Expect(ok).To(BeTrue(), "%v is not declared in metrics.KnownKubeletMetrics", metric)
+	Expect(snapshot[metric]).NotTo(BeEmpty(), "expected %v to have at least one sample", metric)
+
+	allowedLabels := sets.NewString(allowed...)
+	for _, sample := range snapshot[metric] {
+		for label := range sample.Metric {
+			if label == model.MetricNameLabel {
+				continue
+			}
+			Expect(allowedLabels.Has(string(label))).To(BeTrue(),
+				"%v%v has label %q not declared in KnownKubeletMetrics", metric, sample.Metric, label)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"strings"
+
+	"k8s.io/kubernetes/pkg/api"
+	client "k8s.io/kubernetes/pkg/client/unversioned"
+	"k8s.io/kubernetes/pkg/util/sets"
+)
+
+// ControlPlaneTopology describes how a live cluster's control plane is
+// reachable, resolved by probing the API rather than assumed from static
+// provider capabilities (see HasRegisteredMaster). Suites that need to pick
+// between proxying through the apiserver and falling back to SSH -- today
+// duplicated ad hoc wherever they list Nodes looking for a "-master" suffix
+// -- should resolve one of these once instead of re-implementing the scan.
+type ControlPlaneTopology struct {
+	// HasRegisteredMaster is true if a Node with the conventional
+	// "-master" name suffix was found in the live Node list.
+	HasRegisteredMaster bool
+	// MasterNodeName is the matching Node's name, valid only if
+	// HasRegisteredMaster is true.
+	MasterNodeName string
+	// ReachableComponents is the set of control-plane component names
+	// (e.g. "scheduler", "controller-manager", "etcd-0") the apiserver's
+	// componentstatuses currently reports as healthy.
+	ReachableComponents sets.String
+}
+
+// ResolveControlPlaneTopology lists c's Nodes and componentstatuses once and
+// returns the resulting ControlPlaneTopology, so callers like the restart
+// and upgrade suites can decide how to reach a control-plane component
+// without each re-listing Nodes to look for the master themselves.
+func ResolveControlPlaneTopology(c *client.Client) (ControlPlaneTopology, error) {
+	nodes, err := c.Nodes().List(api.ListOptions{})
+	if err != nil {
+		return ControlPlaneTopology{}, err
+	}
+	topology := ControlPlaneTopology{ReachableComponents: sets.NewString()}
+	for _, node := range nodes.Items {
+		if strings.HasSuffix(node.Name, "master") {
+			topology.HasRegisteredMaster = true
+			topology.MasterNodeName = node.Name
+			break
+		}
+	}
+
+	statuses, err := c.ComponentStatuses().List(api.ListOptions{})
+	if err != nil {
+		return ControlPlaneTopology{}, err
+	}
+	for _, status := range statuses.Items {
+		for _, condition := range status.Conditions {
+			if condition.Type == api.ComponentHealthy && condition.Status == api.ConditionTrue {
+				topology.ReachableComponents.Insert(status.Name)
+			}
+		}
+	}
+	return topology, nil
+}
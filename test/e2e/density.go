@@ -32,6 +32,7 @@ import (
 	controllerframework "k8s.io/kubernetes/pkg/controller/framework"
 	"k8s.io/kubernetes/pkg/fields"
 	"k8s.io/kubernetes/pkg/labels"
+	"k8s.io/kubernetes/pkg/metrics"
 	"k8s.io/kubernetes/pkg/runtime"
 	"k8s.io/kubernetes/pkg/util"
 	"k8s.io/kubernetes/pkg/watch"
@@ -118,10 +119,14 @@ var _ = Describe("Density [Skipped]", func() {
 
 	// Gathers data prior to framework namespace teardown
 	AfterEach(func() {
-		// Verify latency metrics.
-		highLatencyRequests, err := HighLatencyRequests(c)
+		// Verify latency metrics, scraped through a MetricsGrabber so a
+		// violation is attributed to the verb/resource that caused it
+		// instead of just a saturating high-latency-request count.
+		// GrabberHighLatencyRequests applies --slo-policy itself.
+		grabber, err := metrics.NewMetricsGrabber(c, false, false, false, true)
+		expectNoError(err)
+		_, err = GrabberHighLatencyRequests(c, grabber)
 		expectNoError(err)
-		Expect(highLatencyRequests).NotTo(BeNumerically(">", 0), "There should be no high-latency requests")
 
 		// Verify scheduler metrics.
 		// TODO: Reset metrics at the beginning of the test.
@@ -36,6 +36,7 @@ import (
 	"k8s.io/kubernetes/pkg/client/unversioned/clientcmd"
 	"k8s.io/kubernetes/pkg/cloudprovider"
 	gcecloud "k8s.io/kubernetes/pkg/cloudprovider/providers/gce"
+	"k8s.io/kubernetes/pkg/metrics"
 	"k8s.io/kubernetes/pkg/util"
 )
 
@@ -91,7 +92,18 @@ func init() {
 	flag.BoolVar(&testContext.GatherKubeSystemResourceUsageData, "gather-resource-usage", false, "If set to true framework will be monitoring resource usage of system add-ons in (some) e2e tests.")
 	flag.BoolVar(&testContext.GatherLogsSizes, "gather-logs-sizes", false, "If set to true framework will be monitoring logs sizes on all machines running e2e tests.")
 	flag.BoolVar(&testContext.GatherMetricsAfterTest, "gather-metrics-at-teardown", false, "If set to true framwork will gather metrics from all components after each test.")
+	flag.BoolVar(&testContext.GatherMetricsBeforeTest, "gather-metrics-at-setup", false, "If set to true framework will gather a baseline metrics snapshot from all components before each test.")
 	flag.StringVar(&testContext.OutputPrintType, "output-print-type", "hr", "Comma separated list: 'hr' for human readable summaries 'json' for JSON ones.")
+	flag.StringVar(&testContext.SLOConfigFile, "slo-config-file", "", "Path to a YAML file of SLO latency thresholds, scaled by cluster node count (see test/e2e/slo_config.go). If unset, the suite's built-in defaults are used.")
+	flag.StringVar(&testContext.UnknownMetricsPolicy, "unknown-metrics-policy", UnknownMetricsPolicyFail, "What to do when a scraped component reports an unrecognized metric: 'fail' or 'warn'.")
+	flag.StringVar(&testContext.InvalidLabelsPolicy, "invalid-labels-policy", ValidationPolicyFail, "What to do when a scraped component reports an unrecognized or malformed metric label: 'fail' or 'warn'.")
+	flag.StringVar(&testContext.SLOPolicy, "slo-policy", ValidationPolicyFail, "What to do when a latency SLO (API call or pod startup) is breached: 'fail' or 'warn'.")
+	flag.StringVar(&testContext.SanityPolicy, "sanity-policy", ValidationPolicyFail, "What to do when a metrics sanity check (e.g. a cumulative counter going negative or spiking across a component restart) fails: 'fail' or 'warn'.")
+	flag.IntVar(&testContext.MetricsGrabParallelism, "metrics-grab-parallelism", metrics.DefaultMetricsGrabberOptions().Parallelism, "Number of kubelets to scrape concurrently when grabbing cluster-wide metrics.")
+	flag.StringVar(&testContext.KubeletMetricsBearerToken, "kubelet-metrics-bearer-token", "", "Bearer token authorized for the nodes/metrics resource. If set, kubelet metrics tests scrape each kubelet's secure port directly with this token instead of relying on the apiserver's node proxy subresource, so they can run on providers that lock the proxy down (e.g. GKE).")
+	flag.Float64Var(&testContext.KubeletScrapeQPS, "kubelet-metrics-qps", 0, "If positive, caps how many times per second a MetricsGrabber will scrape any single kubelet's metrics endpoint. Zero (the default) leaves kubelet scraping unthrottled.")
+	flag.IntVar(&testContext.KubeletScrapeBurst, "kubelet-metrics-burst", 1, "Token-bucket burst size paired with --kubelet-metrics-qps. Ignored when --kubelet-metrics-qps is zero.")
+	flag.StringVar(&testContext.APIServerMetricsPath, "apiserver-metrics-path", "", "Server-relative path to fetch the apiserver's own /metrics from, for clusters that front the apiserver with a path-rewriting proxy. Empty uses the unprefixed \"/metrics\".")
 }
 
 func TestE2E(t *testing.T) {
@@ -183,6 +195,14 @@ func TestE2E(t *testing.T) {
 	if *reportDir != "" {
 		r = append(r, reporters.NewJUnitReporter(path.Join(*reportDir, fmt.Sprintf("junit_%02d.xml", config.GinkgoConfig.ParallelNode))))
 	}
+	r = append(r, NewTestMetricsReporter())
+	r = append(r, NewRuntimeErrorRateReporter(c))
+	r = append(r, NewMetricsGrabberHealthReporter(c))
+	r = append(r, NewAPICallAttributionReporter(c))
+	sloReporter = NewSLOReporter(testContext.OutputDir)
+	r = append(r, sloReporter)
+	unknownMetricsReporter = NewUnknownMetricsReporter(testContext.OutputDir)
+	r = append(r, unknownMetricsReporter)
 	glog.Infof("Starting e2e run; %q", runId)
 	ginkgo.RunSpecsWithDefaultAndCustomReporters(t, "Kubernetes e2e suite", r)
 }
@@ -42,6 +42,11 @@ type Framework struct {
 	Client                   *client.Client
 	NamespaceDeletionTimeout time.Duration
 
+	// BaselineMetrics holds the snapshot grabbed in beforeEach when
+	// testContext.GatherMetricsBeforeTest is set, so tests can diff it
+	// against a later grab without each one doing its own bookkeeping.
+	BaselineMetrics *metrics.MetricsCollection
+
 	gatherer containerResourceGatherer
 	// Constraints that passed to a check which is exectued after data is gathered to
 	// see if 99% of results are within acceptable bounds. It as to be injected in the test,
@@ -110,6 +115,22 @@ func (f *Framework) beforeEach() {
 			f.logsSizeWaitGroup.Done()
 		}()
 	}
+
+	if testContext.GatherMetricsBeforeTest {
+		// TODO: enable Scheduler and ControllerManager metrics grabbing when Master's Kubelet will be registered.
+		grabber, err := metrics.NewMetricsGrabber(c, true, false, false, true)
+		if err != nil {
+			Logf("Failed to create MetricsGrabber. Skipping baseline metrics gathering.")
+		} else {
+			grabber.SetParallelism(testContext.MetricsGrabParallelism)
+			grabber.SetKubeletScrapeRateLimit(float32(testContext.KubeletScrapeQPS), testContext.KubeletScrapeBurst)
+			received, err := grabber.Grab(nil)
+			if err != nil {
+				logGrabFailure("grabbing baseline metrics, see Health for which", err)
+			}
+			f.BaselineMetrics = &received
+		}
+	}
 }
 
 // afterEach deletes the namespace, after reading its events.
@@ -154,12 +175,22 @@ func (f *Framework) afterEach() {
 		if err != nil {
 			Logf("Failed to create MetricsGrabber. Skipping metrics gathering.")
 		} else {
+			grabber.SetParallelism(testContext.MetricsGrabParallelism)
+			grabber.SetKubeletScrapeRateLimit(float32(testContext.KubeletScrapeQPS), testContext.KubeletScrapeBurst)
 			received, err := grabber.Grab(nil)
 			if err != nil {
-				Logf("MetricsGrabber failed grab metrics. Skipping metrics gathering.")
+				logGrabFailure("grabbing metrics, see Health for which", err)
+			}
+			if f.BaselineMetrics != nil {
+				checkNoUnexpectedRestarts(*f.BaselineMetrics, received)
+			}
+			summary := NewMetricsForE2E(received)
+			if schedulingLatency, err := getSchedulingLatency(f.Client); err != nil {
+				Logf("Failed to gather scheduling latency: %v", err)
 			} else {
-				summaries = append(summaries, (*MetricsForE2E)(&received))
+				summary.SchedulingLatency = schedulingLatency
 			}
+			summaries = append(summaries, summary)
 		}
 	}
 
@@ -23,6 +23,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/metrics"
 	"k8s.io/kubernetes/pkg/util"
 )
 
@@ -59,6 +60,21 @@ var _ = Describe("Garbage collector [Slow]", func() {
 		pods, err := f.Client.Pods(f.Namespace.Name).List(api.ListOptions{})
 		Expect(err).NotTo(HaveOccurred())
 		Expect(len(pods.Items)).To(BeNumerically("==", 100))
+
+		By("Checking that the gc controller's own metrics agree with what was observed")
+		grabber, err := metrics.NewMetricsGrabber(f.Client, false, true, false, false)
+		if err != nil {
+			logGrabFailure("checking GC controller metrics", err)
+		} else if response, err := grabber.GrabFromControllerManager(nil); err != nil {
+			logGrabFailure("checking GC controller metrics", err)
+		} else {
+			deletedSamples := response.DeletedPodsCount()
+			errorSamples := response.DeletePodErrorsCount()
+			deleted := metrics.SumValues(deletedSamples)
+			errors := metrics.SumValues(errorSamples)
+			Expect(deleted).To(BeNumerically(">=", 900), "top reporting series:\n%v", metrics.FormatTopSamples(deletedSamples, 5))
+			Expect(errors).To(BeNumerically("==", 0), "top offending series:\n%v", metrics.FormatTopSamples(errorSamples, 5))
+		}
 	})
 })
 
@@ -21,8 +21,11 @@ import (
 
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/apis/extensions"
+	client "k8s.io/kubernetes/pkg/client/unversioned"
+	"k8s.io/kubernetes/pkg/metrics"
 
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
 )
 
 const (
@@ -87,6 +90,29 @@ func (scaleTest *HPAScaleTest) run(name, kind string, rc *ResourceConsumer, f *F
 	rc.EnsureDesiredReplicas(scaleTest.firstScale, scaleTest.firstScaleStasis)
 	rc.ConsumeCPU(scaleTest.cpuBurst)
 	rc.WaitForReplicas(scaleTest.secondScale)
+	checkHPAControllerMetrics(f.Client)
+}
+
+// checkHPAControllerMetrics asserts that the HPA control loop reconciled at
+// least once and didn't hit metric-fetch errors while driving the scale
+// events above, so a scale timeout reads as "HPA couldn't reach heapster"
+// rather than a generic failure. Best-effort: a grab failure is logged, not
+// fatal, since the scale assertions above already cover behavior.
+func checkHPAControllerMetrics(c *client.Client) {
+	grabber, err := metrics.NewMetricsGrabber(c, false, true, false, false)
+	if err != nil {
+		logGrabFailure("checking HPA controller metrics", err)
+		return
+	}
+	response, err := grabber.GrabFromControllerManager(nil)
+	if err != nil {
+		logGrabFailure("checking HPA controller metrics", err)
+		return
+	}
+	reconcileSamples := response.HPAReconcileCount()
+	fetchErrorSamples := response.HPAMetricFetchErrorsCount()
+	Expect(metrics.SumValues(reconcileSamples)).To(BeNumerically(">", 0), "top reporting series:\n%v", metrics.FormatTopSamples(reconcileSamples, 5))
+	Expect(metrics.SumValues(fetchErrorSamples)).To(BeNumerically("==", 0), "top offending series:\n%v", metrics.FormatTopSamples(fetchErrorSamples, 5))
 }
 
 func scaleUp(name, kind string, rc *ResourceConsumer, f *Framework) {
@@ -0,0 +1,33 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	. "github.com/onsi/ginkgo"
+)
+
+// kube-proxy now exposes sync_proxy_rules_failure_total and
+// iptables_rules_total on /metrics (see pkg/proxy/iptables/metrics.go and
+// pkg/metrics.KnownKubeProxyMetrics), but MetricsGrabber has no path to
+// reach them: unlike the kubelet, the apiserver's nodes/proxy subresource
+// only forwards to the kubelet's own HTTP server, not to other processes
+// listening on the node. Placeholder documents the assertion to add once a
+// GrabFromKubeProxy path exists: failure counters stay at zero across a
+// Service create, while the rule-count gauge increases.
+var _ = Describe("kube-proxy iptables metrics", func() {
+	PIt("should keep sync failure counters at zero while rule counts increase after Service creation", func() {})
+})
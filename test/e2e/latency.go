@@ -56,6 +56,7 @@ var _ = Describe("Latency [Skipped]", func() {
 		// Verify latency metrics
 		highLatencyRequests, err := HighLatencyRequests(c)
 		expectNoError(err)
+		recordSLOResult("HighLatencyRequests", highLatencyRequests == 0, "%d high-latency request(s) observed", highLatencyRequests)
 		Expect(highLatencyRequests).NotTo(BeNumerically(">", 0), "There should be no high-latency requests")
 	})
 
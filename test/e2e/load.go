@@ -26,6 +26,7 @@ import (
 	"k8s.io/kubernetes/pkg/api"
 	client "k8s.io/kubernetes/pkg/client/unversioned"
 	"k8s.io/kubernetes/pkg/labels"
+	"k8s.io/kubernetes/pkg/metrics"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -52,16 +53,37 @@ var _ = Describe("Load capacity [Skipped]", func() {
 	var nodeCount int
 	var ns string
 	var configs []*RCConfig
+	var grabber *metrics.MetricsGrabber
+	var baselineSchedulingCount float64
+	var loadStart time.Time
 
 	// Gathers metrics before teardown
 	// TODO add flag that allows to skip cleanup on failure
 	AfterEach(func() {
 		deleteAllRC(configs)
 
-		// Verify latency metrics
-		highLatencyRequests, err := HighLatencyRequests(c)
+		// Verify apiserver latency, scraped through the MetricsGrabber so a
+		// violation is attributed to the verb/resource that caused it.
+		// GrabberHighLatencyRequests applies --slo-policy itself.
+		_, err := GrabberHighLatencyRequests(c, grabber)
 		expectNoError(err, "Too many instances metrics above the threshold")
-		Expect(highLatencyRequests).NotTo(BeNumerically(">", 0))
+
+		// Verify the apiserver didn't report request errors during the load.
+		apiServerMetrics, err := grabber.GrabFromApiServer(nil)
+		expectNoError(err)
+		errorRate := metrics.RequestErrorRate(apiServerMetrics.RequestCount())
+		recordSLOResult("APIServerErrorRate", errorRate == 0, "%.4f error rate", errorRate)
+		Expect(errorRate).To(BeNumerically("==", 0), "apiserver reported request errors during the load test")
+
+		// Verify the scheduler actually made progress over the load's
+		// lifetime; a throughput of zero under load is a scheduler hang,
+		// not just a slow run.
+		schedulerMetrics, err := grabber.GrabFromScheduler(nil)
+		expectNoError(err)
+		elapsed := time.Since(loadStart)
+		throughput := (metrics.SumValues(schedulerMetrics.SchedulingCount()) - baselineSchedulingCount) / elapsed.Seconds()
+		recordSLOResult("SchedulerThroughput", throughput > 0, "%.2f schedules/sec over %v", throughput, elapsed)
+		Expect(throughput).To(BeNumerically(">", 0), "scheduler made no progress during the load test")
 	})
 
 	// Explicitly put here, to delete namespace at the end of the test
@@ -83,6 +105,13 @@ var _ = Describe("Load capacity [Skipped]", func() {
 		expectNoError(err)
 
 		expectNoError(resetMetrics(c))
+
+		grabber, err = metrics.NewMetricsGrabber(c, false, true, false, true)
+		expectNoError(err)
+		schedulerMetrics, err := grabber.GrabFromScheduler(nil)
+		expectNoError(err)
+		baselineSchedulingCount = metrics.SumValues(schedulerMetrics.SchedulingCount())
+		loadStart = time.Now()
 	})
 
 	type Load struct {
@@ -0,0 +1,140 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"strings"
+
+	"k8s.io/kubernetes/pkg/master/ports"
+	"k8s.io/kubernetes/pkg/metrics"
+	"k8s.io/kubernetes/pkg/util/wait"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/common/model"
+)
+
+// assertCommonMetricsRegistered polls name's snapshot until every family in
+// metrics.CommonMetrics has at least one sample, or restartTimeout elapses.
+// These families are registered by the standard Go/Prometheus client
+// instrumentation every instrumented binary wires up at startup, so seeing
+// them populated again is evidence the restarted process came all the way
+// back up rather than merely answering /healthz.
+func assertCommonMetricsRegistered(name string, grab func() (metrics.Metrics, error)) {
+	var missing []string
+	err := wait.Poll(restartPollInterval, restartTimeout, func() (bool, error) {
+		snapshot, err := grab()
+		if err != nil {
+			return false, nil
+		}
+		missing = nil
+		for metric := range metrics.CommonMetrics {
+			if len(snapshot[metric]) == 0 {
+				missing = append(missing, metric)
+			}
+		}
+		return len(missing) == 0, nil
+	})
+	expectNoError(err, "%v metrics did not fully re-register within %v of restart; still missing: %v", name, restartTimeout, missing)
+}
+
+// Flaky for the same reasons as the other DaemonRestart tests: it depends on
+// SSH-killing a component and racing its supervisor to bring it back.
+var _ = Describe("MetricsAvailability [Disruptive] [Flaky]", func() {
+	framework := NewFramework("metrics-availability")
+
+	It("should make scheduler and controller-manager metrics available again shortly after a restart", func() {
+		// These tests require SSH.
+		SkipUnlessProviderIs("gce", "aws")
+
+		schedulerRestarter := NewRestartConfig(
+			getMasterHost(), "kube-scheduler", ports.SchedulerPort, restartPollInterval, restartTimeout)
+		controllerManagerRestarter := NewRestartConfig(
+			getMasterHost(), "kube-controller-manager", ports.ControllerManagerPort, restartPollInterval, restartTimeout)
+
+		schedulerRestarter.restart()
+		controllerManagerRestarter.restart()
+
+		grabber, err := metrics.NewMetricsGrabber(framework.Client, false, true, true, false)
+		expectNoError(err)
+
+		assertCommonMetricsRegistered("Scheduler", func() (metrics.Metrics, error) {
+			m, err := grabber.GrabFromScheduler(nil)
+			return metrics.Metrics(m), err
+		})
+		assertCommonMetricsRegistered("ControllerManager", func() (metrics.Metrics, error) {
+			m, err := grabber.GrabFromControllerManager(nil)
+			return metrics.Metrics(m), err
+		})
+	})
+
+	It("should not report negative or spiking rates when a component restarts mid-measurement", func() {
+		// These tests require SSH.
+		SkipUnlessProviderIs("gce", "aws")
+
+		grabber, err := metrics.NewMetricsGrabber(framework.Client, false, true, false, false)
+		expectNoError(err)
+		baseline, err := grabber.GrabFromScheduler(nil)
+		expectNoError(err)
+
+		restarter := NewRestartConfig(
+			getMasterHost(), "kube-scheduler", ports.SchedulerPort, restartPollInterval, restartTimeout)
+		restarter.restart()
+
+		current, err := grabber.GrabFromScheduler(nil)
+		expectNoError(err)
+		Expect(metrics.RestartedBetween(metrics.Metrics(baseline), metrics.Metrics(current))).To(BeTrue(),
+			"expected process_start_time_seconds to show the scheduler restarted between the two snapshots")
+
+		phase := metrics.SchedulerMetrics(metrics.Metrics(current).SubtractBaseline(metrics.Metrics(baseline)))
+		for name, samples := range phase {
+			if !isMonotonicMetricName(name) {
+				continue
+			}
+			bound := sumSampleValues(current[name])
+			for _, sample := range samples {
+				warnOrFail(testContext.SanityPolicy, float64(sample.Value) >= 0,
+					"phase value for %v%v went negative across a restart: %v", name, sample.Metric, sample.Value)
+				warnOrFail(testContext.SanityPolicy, float64(sample.Value) <= bound,
+					"phase value for %v%v (%v) exceeded the component's total since restart (%v), indicating a spike from an incorrectly computed rate", name, sample.Metric, sample.Value, bound)
+			}
+		}
+	})
+})
+
+// isMonotonicMetricName mirrors pkg/metrics' private cumulative-counter
+// naming convention (_total, _count, _sum suffixes), since the rate-spike
+// assertion above only makes sense for counters, not gauges or quantiles.
+func isMonotonicMetricName(name string) bool {
+	for _, suffix := range []string{"_total", "_count", "_sum"} {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// sumSampleValues adds up a metric family's sample values, so the restart
+// test can bound a phase counter's value by the total the component has
+// reported since it came back up.
+func sumSampleValues(samples model.Samples) float64 {
+	var sum float64
+	for _, sample := range samples {
+		sum += float64(sample.Value)
+	}
+	return sum
+}
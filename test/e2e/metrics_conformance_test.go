@@ -0,0 +1,33 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"k8s.io/kubernetes/pkg/metrics"
+
+	. "github.com/onsi/ginkgo"
+)
+
+var _ = Describe("Metrics exposition conformance", func() {
+	framework := NewFramework("metrics-exposition-conformance")
+
+	It("should serve equivalent family sets in the text and protobuf exposition formats", func() {
+		grabber, err := metrics.NewMetricsGrabber(framework.Client, false, false, false, true)
+		expectNoError(err)
+		expectNoError(grabber.CheckApiServerTextProtobufConformance(metrics.DefaultMetricsGrabberOptions().ApiServerTimeout))
+	})
+})
@@ -0,0 +1,41 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"k8s.io/kubernetes/pkg/metrics"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Metrics endpoint consistency", func() {
+	framework := NewFramework("metrics-endpoint-consistency")
+
+	It("should serve the same metric families on the kubelet's secure and read-only ports", func() {
+		nodes := ListSchedulableNodesOrDie(framework.Client)
+		Expect(nodes.Items).NotTo(BeEmpty(), "expected at least one schedulable node")
+
+		grabber, err := metrics.NewMetricsGrabber(framework.Client, true, false, false, false)
+		expectNoError(err)
+
+		onlySecure, onlyInsecure, err := grabber.CompareKubeletEndpoints(nodes.Items[0].Name)
+		expectNoError(err)
+		Expect(onlySecure).To(BeEmpty(), "families only present on the kubelet's secure endpoint")
+		Expect(onlyInsecure).To(BeEmpty(), "families only present on the kubelet's insecure, read-only endpoint")
+	})
+})
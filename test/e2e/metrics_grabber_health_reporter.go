@@ -0,0 +1,73 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"github.com/onsi/ginkgo/config"
+	"github.com/onsi/ginkgo/types"
+
+	client "k8s.io/kubernetes/pkg/client/unversioned"
+	"k8s.io/kubernetes/pkg/metrics"
+)
+
+// MetricsGrabberHealthReporter is a Ginkgo reporter that keeps one
+// MetricsGrabber alive for the whole suite and, at the end, logs the
+// grabber's own self-instrumentation (scrape counts, retries, failures and
+// latency per component, see metrics.MetricsGrabber.Stats). When
+// metrics collection looks unhealthy during a run, this is what tells
+// whether the grabber itself was struggling -- the test harness -- rather
+// than the cluster being tested.
+type MetricsGrabberHealthReporter struct {
+	client  *client.Client
+	grabber *metrics.MetricsGrabber
+}
+
+// NewMetricsGrabberHealthReporter creates a MetricsGrabberHealthReporter
+// ready to be passed to ginkgo.RunSpecsWithDefaultAndCustomReporters.
+func NewMetricsGrabberHealthReporter(c *client.Client) *MetricsGrabberHealthReporter {
+	return &MetricsGrabberHealthReporter{client: c}
+}
+
+func (r *MetricsGrabberHealthReporter) SpecSuiteWillBegin(config config.GinkgoConfigType, summary *types.SuiteSummary) {
+	grabber, err := metrics.NewMetricsGrabber(r.client, false, false, false, true)
+	if err != nil {
+		logGrabFailure("creating MetricsGrabber for self-instrumentation reporting", err)
+		return
+	}
+	r.grabber = grabber
+}
+
+func (r *MetricsGrabberHealthReporter) BeforeSuiteDidRun(setupSummary *types.SetupSummary) {}
+
+func (r *MetricsGrabberHealthReporter) SpecWillRun(specSummary *types.SpecSummary) {}
+
+func (r *MetricsGrabberHealthReporter) SpecDidComplete(specSummary *types.SpecSummary) {}
+
+func (r *MetricsGrabberHealthReporter) AfterSuiteDidRun(setupSummary *types.SetupSummary) {}
+
+func (r *MetricsGrabberHealthReporter) SpecSuiteDidEnd(summary *types.SuiteSummary) {
+	if r.grabber == nil {
+		return
+	}
+	if _, err := r.grabber.GrabFromApiServer(nil); err != nil {
+		logGrabFailure("running self-check scrape", err)
+	}
+	for component, stats := range r.grabber.Stats() {
+		Logf("MetricsGrabber self-instrumentation for %v: %d scrapes, %d retried, %d retries, %d failed, last took %v",
+			component, stats.Scrapes, stats.Retried, stats.Retries, stats.Failed, stats.LastDuration)
+	}
+}
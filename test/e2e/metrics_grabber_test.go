@@ -17,21 +17,58 @@ limitations under the License.
 package e2e
 
 import (
+	"fmt"
 	"strings"
 
-	"k8s.io/kubernetes/pkg/api"
 	client "k8s.io/kubernetes/pkg/client/unversioned"
 	"k8s.io/kubernetes/pkg/metrics"
 	"k8s.io/kubernetes/pkg/util/sets"
+	"k8s.io/kubernetes/pkg/version"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
 
-// Missing = Assumed minus Observed, Invalid = Observed minus Assumed
-func validateLabelSet(labelSet map[string][]string, data metrics.Metrics, invalidLabels map[string]sets.String, missingLabels map[string]sets.String) {
+// unknownMetricsReporter accumulates unrecognized metrics/labels reported
+// via checkUnknownMetrics/checkMetrics for the JSON triage report written at
+// suite end. It's set by TestE2E before the suite runs; nil in any other
+// context (e.g. a standalone unit test), where recording is a silent no-op.
+var unknownMetricsReporter *UnknownMetricsReporter
+
+// serverBuild returns the GitVersion of the component client is talking to,
+// for tagging a freshly-recorded unknown metric/label with the build it was
+// first seen on.
+func serverBuild(c client.Interface) string {
+	version, err := c.Discovery().ServerVersion()
+	if err != nil {
+		return "unknown"
+	}
+	return version.GitVersion
+}
+
+// isSkewed reports whether componentVersion is an older minor release than
+// the test binary's own version -- supported version skew -- logging the
+// downgrade so a relaxed validation run is visible in the test output
+// instead of silently looking like a clean pass.
+func isSkewed(component, componentVersion string) bool {
+	binaryVersion := version.Get().GitVersion
+	if !metrics.IsOlderMinorVersion(componentVersion, binaryVersion) {
+		return false
+	}
+	Logf("%v reports version %v, older than the test binary's %v (supported skew); relaxing metrics validation to %v's expected set", component, componentVersion, binaryVersion, componentVersion)
+	return true
+}
+
+// Missing = Assumed minus Observed, Invalid = Observed minus Assumed. If
+// skewed is true, a metric family missing from data is assumed to not
+// exist yet on the older release the component is running, rather than
+// being treated as a validation failure.
+func validateLabelSet(labelSet map[string][]string, data metrics.Metrics, invalidLabels map[string]sets.String, missingLabels map[string]sets.String, skewed bool) {
 	for metric, labels := range labelSet {
 		vector, found := data[metric]
+		if !found && skewed {
+			continue
+		}
 		Expect(found).To(Equal(true))
 		if found && len(vector) > 0 {
 			for _, observation := range vector {
@@ -59,14 +96,78 @@ func validateLabelSet(labelSet map[string][]string, data metrics.Metrics, invali
 	}
 }
 
-func checkMetrics(response metrics.Metrics, assumedMetrics map[string][]string) {
+// Values for --unknown-metrics-policy. Aliased to the shared
+// ValidationPolicy* constants so these exported names -- already wired to
+// the --unknown-metrics-policy flag -- keep working unchanged.
+const (
+	UnknownMetricsPolicyFail = ValidationPolicyFail
+	UnknownMetricsPolicyWarn = ValidationPolicyWarn
+)
+
+// checkUnknownMetrics applies --unknown-metrics-policy to a set of metric
+// names a grab call didn't recognize: under the default "fail" policy it's
+// a test failure, so periodic jobs catch metric-list drift; under "warn" it
+// only logs, so curating the metric list doesn't block unrelated presubmits.
+// Either way, each unknown metric is recorded to the JSON triage report.
+func checkUnknownMetrics(c client.Interface, component string, unknownMetrics sets.String) {
+	if unknownMetrics.Len() == 0 {
+		return
+	}
+	observer := CurrentGinkgoTestDescription().FullTestText()
+	anyNew := unknownMetricsReporter == nil
+	if unknownMetricsReporter != nil {
+		build := serverBuild(c)
+		for _, metric := range unknownMetrics.List() {
+			if unknownMetricsReporter.RecordUnknownMetric(component, metric, build, observer) {
+				anyNew = true
+			}
+		}
+	}
+	if !anyNew {
+		Logf("%v's unrecognized metric(s) were already reported by another test this run, see unknown_metrics.json: %v", component, unknownMetrics.List())
+		return
+	}
+	warnOrFail(testContext.UnknownMetricsPolicy, false,
+		"grabbed metrics included unrecognized metric(s): %v", unknownMetrics.List())
+}
+
+// checkMetrics applies --invalid-labels-policy to the labels and values of a
+// scraped component's metrics: under the default "fail" policy an unknown or
+// malformed label is a test failure; under "warn" it only logs, the same
+// tradeoff --unknown-metrics-policy makes for unrecognized metric names.
+// expected is an optional list of targeted, per-family assertions (built via
+// metrics.Expect()) that a caller outside this file's known-metrics schemas
+// wants checked against the same response, e.g. a component-specific test
+// asserting one counter incremented; a violation is subject to the same
+// --invalid-labels-policy as the rest of this function's checks.
+func checkMetrics(c client.Interface, component string, response metrics.Metrics, assumedMetrics map[string][]string, skewed bool, expected ...*metrics.ExpectedMetric) {
 	invalidLabels := make(map[string]sets.String)
 	unknownLabels := make(map[string]sets.String)
-	validateLabelSet(metrics.CommonMetrics, response, invalidLabels, unknownLabels)
-	validateLabelSet(assumedMetrics, response, invalidLabels, unknownLabels)
+	validateLabelSet(metrics.CommonMetrics, response, invalidLabels, unknownLabels, skewed)
+	validateLabelSet(assumedMetrics, response, invalidLabels, unknownLabels, skewed)
+
+	if unknownMetricsReporter != nil {
+		build := serverBuild(c)
+		observer := CurrentGinkgoTestDescription().FullTestText()
+		for metric, labels := range unknownLabels {
+			if !unknownMetricsReporter.RecordUnknownLabels(component, metric, labels, build, observer) {
+				Logf("%v's unrecognized labels on %v were already reported by another test this run, see unknown_metrics.json", component, metric)
+				delete(unknownLabels, metric)
+			}
+		}
+	}
 
-	Expect(unknownLabels).To(BeEmpty())
-	Expect(invalidLabels).To(BeEmpty())
+	policy := testContext.InvalidLabelsPolicy
+	warnOrFail(policy, len(unknownLabels) == 0, "%v reported unknown metric labels: %v", component, unknownLabels)
+	warnOrFail(policy, len(invalidLabels) == 0, "%v reported invalid metric labels: %v", component, invalidLabels)
+	if invalidValues := metrics.InvalidLabelValues(response); len(invalidValues) > 0 {
+		warnOrFail(policy, false, "%v reported metric label values that failed validation: %v", component, invalidValues)
+	}
+	for _, spec := range expected {
+		for _, problem := range spec.Check(response) {
+			warnOrFail(policy, false, "%v: %v", component, problem)
+		}
+	}
 }
 
 var _ = Describe("MetricsGrabber", func() {
@@ -79,6 +180,8 @@ var _ = Describe("MetricsGrabber", func() {
 		expectNoError(err)
 		grabber, err = metrics.NewMetricsGrabber(c, true, true, true, true)
 		expectNoError(err)
+		grabber.SetKubeletScrapeRateLimit(float32(testContext.KubeletScrapeQPS), testContext.KubeletScrapeBurst)
+		grabber.SetAPIServerMetricsPath(testContext.APIServerMetricsPath)
 	})
 
 	It("should grab all metrics from API server.", func() {
@@ -86,71 +189,227 @@ var _ = Describe("MetricsGrabber", func() {
 		unknownMetrics := sets.NewString()
 		response, err := grabber.GrabFromApiServer(unknownMetrics)
 		expectNoError(err)
-		Expect(unknownMetrics).To(BeEmpty())
+		checkUnknownMetrics(c, "apiserver", unknownMetrics)
 
-		checkMetrics(metrics.Metrics(response), metrics.KnownApiServerMetrics)
+		build := serverBuild(c)
+		checkMetrics(c, "apiserver", metrics.Metrics(response), metrics.ResolveKnownMetrics(metrics.KnownApiServerMetricsByVersion, build), isSkewed("apiserver", build))
+		Expect(response.WatchCacheSize()).NotTo(BeEmpty())
 	})
 
 	It("should grab all metrics from a Kubelet.", func() {
-		// We run this test only on GCE, as for some reason it flakes in GKE #19468
-		if providerIs("gce") {
-			By("Connecting proxying to Node through the API server")
-			nodes := ListSchedulableNodesOrDie(c)
-			Expect(nodes.Items).NotTo(BeEmpty())
-			unknownMetrics := sets.NewString()
-			response, err := grabber.GrabFromKubelet(nodes.Items[0].Name, unknownMetrics)
+		// The grabber itself falls through a strategy chain (apiserver
+		// proxy, then a direct scrape, then the read-only port) rather than
+		// relying on the apiserver proxy alone, so this no longer needs to
+		// skip on providers that block or flake on proxy scraping (e.g. GKE,
+		// #19468) -- it just uses whichever strategy actually works there.
+		kubeletGrabber := grabber
+		if testContext.KubeletMetricsBearerToken != "" {
+			By("Connecting directly to the Node's secure kubelet port with a bearer token, if the apiserver proxy is unavailable")
+			var err error
+			kubeletGrabber, err = metrics.NewTokenAuthMetricsGrabber(c, testContext.KubeletMetricsBearerToken, true, false, false, false)
 			expectNoError(err)
-			Expect(unknownMetrics).To(BeEmpty())
-
-			checkMetrics(metrics.Metrics(response), metrics.KnownKubeletMetrics)
 		}
+
+		nodes := ListSchedulableNodesOrDie(c)
+		Expect(nodes.Items).NotTo(BeEmpty())
+		unknownMetrics := sets.NewString()
+		response, err := kubeletGrabber.GrabFromKubelet(nodes.Items[0].Name, unknownMetrics)
+		expectNoError(err)
+		checkUnknownMetrics(c, "kubelet", unknownMetrics)
+
+		kubeletVersion := nodes.Items[0].Status.NodeInfo.KubeletVersion
+		checkMetrics(c, "kubelet", metrics.Metrics(response), metrics.ResolveKnownMetrics(metrics.KnownKubeletMetricsByVersion, kubeletVersion), isSkewed("kubelet", kubeletVersion))
 	})
 
 	It("should grab all metrics from a Scheduler.", func() {
 		By("Connecting proxying to Pod through the API server")
-		// Check if master Node is registered
-		nodes, err := c.Nodes().List(api.ListOptions{})
-		expectNoError(err)
-
-		var masterRegistered = false
-		for _, node := range nodes.Items {
-			if strings.HasSuffix(node.Name, "master") {
-				masterRegistered = true
-			}
-		}
-		if !masterRegistered {
-			Logf("Master is node registered. Skipping testing Scheduler metrics.")
-			return
-		}
+		SkipUnlessComponentExposesMetrics(grabber, "scheduler")
 		unknownMetrics := sets.NewString()
 		response, err := grabber.GrabFromScheduler(unknownMetrics)
 		expectNoError(err)
-		Expect(unknownMetrics).To(BeEmpty())
+		checkUnknownMetrics(c, "scheduler", unknownMetrics)
 
-		checkMetrics(metrics.Metrics(response), metrics.KnownSchedulerMetrics)
+		build := serverBuild(c)
+		checkMetrics(c, "scheduler", metrics.Metrics(response), metrics.ResolveKnownMetrics(metrics.KnownSchedulerMetricsByVersion, build), isSkewed("scheduler", build))
 	})
 
 	It("should grab all metrics from a ControllerManager.", func() {
 		By("Connecting proxying to Pod through the API server")
-		// Check if master Node is registered
-		nodes, err := c.Nodes().List(api.ListOptions{})
+		SkipUnlessComponentExposesMetrics(grabber, "controllerManager")
+		unknownMetrics := sets.NewString()
+		response, err := grabber.GrabFromControllerManager(unknownMetrics)
 		expectNoError(err)
+		checkUnknownMetrics(c, "controllerManager", unknownMetrics)
 
-		var masterRegistered = false
-		for _, node := range nodes.Items {
-			if strings.HasSuffix(node.Name, "master") {
-				masterRegistered = true
-			}
-		}
-		if !masterRegistered {
-			Logf("Master is node registered. Skipping testing ControllerManager metrics.")
-			return
-		}
+		build := serverBuild(c)
+		checkMetrics(c, "controllerManager", metrics.Metrics(response), metrics.ResolveKnownMetrics(metrics.KnownControllerManagerMetricsByVersion, build), isSkewed("controllerManager", build))
+		Expect(response.WorkqueueDepth()).NotTo(BeEmpty())
+	})
+
+	// These used to be a single "across any component" spec that silently
+	// logged and moved on when a component's metrics couldn't be grabbed
+	// (e.g. no registered master and an unhealthy componentstatus) --
+	// which left that component's metrics unchecked while the spec still
+	// reported as a pass. Splitting them lets SkipUnlessComponentExposesMetrics
+	// record an explicit, per-component skip reason in the JUnit output
+	// instead.
+
+	It("should report no unrecognized apiserver metrics or labels", func() {
+		build := serverBuild(c)
+		unknownMetrics := sets.NewString()
+		response, err := grabber.GrabFromApiServer(unknownMetrics)
+		expectNoError(err)
+		problems := summarizeMetrics(c, "apiserver", CurrentGinkgoTestDescription().FullTestText(), metrics.Metrics(response), metrics.ResolveKnownMetrics(metrics.KnownApiServerMetricsByVersion, build), unknownMetrics, isSkewed("apiserver", build))
+		logAndExpectNoMetricsProblems("apiserver", problems)
+	})
+
+	It("should report no unrecognized scheduler metrics or labels", func() {
+		SkipUnlessComponentExposesMetrics(grabber, "scheduler")
+		build := serverBuild(c)
+		unknownMetrics := sets.NewString()
+		response, err := grabber.GrabFromScheduler(unknownMetrics)
+		expectNoError(err)
+		problems := summarizeMetrics(c, "scheduler", CurrentGinkgoTestDescription().FullTestText(), metrics.Metrics(response), metrics.ResolveKnownMetrics(metrics.KnownSchedulerMetricsByVersion, build), unknownMetrics, isSkewed("scheduler", build))
+		logAndExpectNoMetricsProblems("scheduler", problems)
+	})
+
+	It("should report no unrecognized controller-manager metrics or labels", func() {
+		SkipUnlessComponentExposesMetrics(grabber, "controllerManager")
+		build := serverBuild(c)
 		unknownMetrics := sets.NewString()
 		response, err := grabber.GrabFromControllerManager(unknownMetrics)
 		expectNoError(err)
-		Expect(unknownMetrics).To(BeEmpty())
+		problems := summarizeMetrics(c, "controllerManager", CurrentGinkgoTestDescription().FullTestText(), metrics.Metrics(response), metrics.ResolveKnownMetrics(metrics.KnownControllerManagerMetricsByVersion, build), unknownMetrics, isSkewed("controllerManager", build))
+		logAndExpectNoMetricsProblems("controllerManager", problems)
+	})
 
-		checkMetrics(metrics.Metrics(response), metrics.KnownControllerManagerMetrics)
+	It("should report no unrecognized kubelet metrics or labels", func() {
+		nodes := ListSchedulableNodesOrDie(c)
+		var problems []string
+		for _, node := range nodes.Items {
+			unknownMetrics := sets.NewString()
+			response, err := grabber.GrabFromKubelet(node.Name, unknownMetrics)
+			expectNoError(err)
+			kubeletVersion := node.Status.NodeInfo.KubeletVersion
+			knownMetrics := metrics.ResolveKnownMetrics(metrics.KnownKubeletMetricsByVersion, kubeletVersion)
+			component := fmt.Sprintf("kubelet/%v", node.Name)
+			problems = append(problems, summarizeMetrics(c, "kubelets", node.Name, metrics.Metrics(response), knownMetrics, unknownMetrics, isSkewed(component, kubeletVersion))...)
+		}
+		logAndExpectNoMetricsProblems("kubelets", problems)
 	})
 })
+
+// logAndExpectNoMetricsProblems logs component's summarizeMetrics findings
+// the same way the metrics-conformance specs always have, then fails the
+// spec if there were any -- so a metrics regression in one component shows
+// up as that component's own spec failing, rather than a single shared
+// spec whose failure message has to be read to find out which component
+// was actually at fault.
+func logAndExpectNoMetricsProblems(component string, problems []string) {
+	if len(problems) == 0 {
+		Logf("[metrics-conformance] %v: OK", component)
+		return
+	}
+	Logf("[metrics-conformance] %v:\n  %v", component, strings.Join(problems, "\n  "))
+	Expect(problems).To(BeEmpty(), "%v reported unrecognized metrics/labels; see the per-component report above", component)
+}
+
+// summarizeMetrics is checkMetrics/checkUnknownMetrics's non-asserting
+// counterpart: instead of failing the spec on the first problem, it records
+// the same triage-report entries and returns every problem found as a
+// human-readable line, so a caller validating many components in one spec
+// can report all of them together instead of stopping at the first.
+//
+// observer identifies who's making this particular observation -- usually
+// the current spec's name, but callers that probe several instances of one
+// component within a single spec (e.g. one "kubelets" spec checking every
+// node) should pass something finer, like the node name. Combined with a
+// component string shared across those instances, this lets the same
+// unrecognized metric observed on every node collapse into a single
+// unknown_metrics.json record (with every observer listed) and a single
+// problem line, instead of one near-identical problem per node.
+func summarizeMetrics(c client.Interface, component, observer string, response metrics.Metrics, assumedMetrics map[string][]string, unknownMetrics sets.String, skewed bool) []string {
+	var problems []string
+
+	if unknownMetrics.Len() > 0 {
+		newMetrics := unknownMetrics.List()
+		if unknownMetricsReporter != nil {
+			build := serverBuild(c)
+			newMetrics = nil
+			for _, metric := range unknownMetrics.List() {
+				if unknownMetricsReporter.RecordUnknownMetric(component, metric, build, observer) {
+					newMetrics = append(newMetrics, metric)
+				}
+			}
+		}
+		if len(newMetrics) > 0 {
+			problems = append(problems, fmt.Sprintf("unrecognized metric(s): %v", newMetrics))
+		}
+	}
+
+	invalidLabels, missingFamilies := collectLabelProblems(metrics.CommonMetrics, response, skewed)
+	moreInvalid, moreMissing := collectLabelProblems(assumedMetrics, response, skewed)
+	for metric, labels := range moreInvalid {
+		invalidLabels[metric] = labels
+	}
+	missingFamilies = append(missingFamilies, moreMissing...)
+
+	if unknownMetricsReporter != nil {
+		build := serverBuild(c)
+		for metric, labels := range invalidLabels {
+			if !unknownMetricsReporter.RecordUnknownLabels(component, metric, labels, build, observer) {
+				delete(invalidLabels, metric)
+			}
+		}
+	}
+	if len(missingFamilies) > 0 {
+		problems = append(problems, fmt.Sprintf("missing metric family/families: %v", missingFamilies))
+	}
+	if len(invalidLabels) > 0 {
+		problems = append(problems, fmt.Sprintf("unrecognized label(s): %v", invalidLabels))
+	}
+	if invalidValues := metrics.InvalidLabelValues(response); len(invalidValues) > 0 {
+		problems = append(problems, fmt.Sprintf("label(s) with unexpected value(s): %v", invalidValues))
+	}
+	return problems
+}
+
+// collectLabelProblems is validateLabelSet's non-asserting counterpart: it
+// returns the same invalid-label findings plus any metric family entirely
+// absent from data, instead of failing the spec immediately on the first
+// missing family. If skewed is true, a missing family is assumed to not
+// exist yet on the older release the component is running, rather than
+// being reported as a problem.
+func collectLabelProblems(labelSet map[string][]string, data metrics.Metrics, skewed bool) (invalidLabels map[string]sets.String, missingFamilies []string) {
+	invalidLabels = make(map[string]sets.String)
+	for metric, labels := range labelSet {
+		vector, found := data[metric]
+		if !found {
+			if !skewed {
+				missingFamilies = append(missingFamilies, metric)
+			}
+			continue
+		}
+		for _, observation := range vector {
+			for label := range observation.Metric {
+				if strings.HasPrefix(string(label), "__") {
+					continue
+				}
+				known := false
+				for _, knownLabel := range labels {
+					if string(label) == knownLabel {
+						known = true
+						break
+					}
+				}
+				if !known {
+					if _, ok := invalidLabels[metric]; !ok {
+						invalidLabels[metric] = sets.NewString()
+					}
+					invalidLabels[metric].Insert(string(label))
+				}
+			}
+		}
+	}
+	return invalidLabels, missingFamilies
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/kubernetes/pkg/metrics"
+
+	"github.com/onsi/gomega/types"
+	"github.com/prometheus/common/model"
+)
+
+// HaveMetric succeeds if the actual metrics.Metrics contains at least one
+// sample for the given metric name.
+func HaveMetric(name string) types.GomegaMatcher {
+	return &haveMetricMatcher{name: name}
+}
+
+type haveMetricMatcher struct {
+	name string
+}
+
+func (m *haveMetricMatcher) Match(actual interface{}) (bool, error) {
+	data, ok := actual.(metrics.Metrics)
+	if !ok {
+		return false, fmt.Errorf("HaveMetric expects a metrics.Metrics, got %T", actual)
+	}
+	return len(data[m.name]) > 0, nil
+}
+
+func (m *haveMetricMatcher) FailureMessage(actual interface{}) string {
+	return fmt.Sprintf("Expected metrics to contain at least one sample for %q", m.name)
+}
+
+func (m *haveMetricMatcher) NegatedFailureMessage(actual interface{}) string {
+	return fmt.Sprintf("Expected metrics not to contain any samples for %q", m.name)
+}
+
+// HaveLabelValues succeeds if the actual metrics.Metrics contains a sample
+// for the given metric name whose labels match all of the given label
+// values.
+func HaveLabelValues(name string, labels map[string]string) types.GomegaMatcher {
+	return &haveLabelValuesMatcher{name: name, labels: labels}
+}
+
+type haveLabelValuesMatcher struct {
+	name   string
+	labels map[string]string
+}
+
+func (m *haveLabelValuesMatcher) Match(actual interface{}) (bool, error) {
+	data, ok := actual.(metrics.Metrics)
+	if !ok {
+		return false, fmt.Errorf("HaveLabelValues expects a metrics.Metrics, got %T", actual)
+	}
+	for _, sample := range data[m.name] {
+		if sampleMatchesLabels(sample, m.labels) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *haveLabelValuesMatcher) FailureMessage(actual interface{}) string {
+	return fmt.Sprintf("Expected metrics to contain a sample for %q with labels %v", m.name, m.labels)
+}
+
+func (m *haveLabelValuesMatcher) NegatedFailureMessage(actual interface{}) string {
+	return fmt.Sprintf("Expected metrics not to contain a sample for %q with labels %v", m.name, m.labels)
+}
+
+// HaveCounterAbove succeeds if the actual metrics.Metrics contains a sample
+// for the given metric name whose value is greater than threshold. When no
+// labels are given, all samples for the metric are summed before comparing.
+func HaveCounterAbove(name string, threshold float64) types.GomegaMatcher {
+	return &haveCounterAboveMatcher{name: name, threshold: threshold}
+}
+
+type haveCounterAboveMatcher struct {
+	name      string
+	threshold float64
+	total     float64
+}
+
+func (m *haveCounterAboveMatcher) Match(actual interface{}) (bool, error) {
+	data, ok := actual.(metrics.Metrics)
+	if !ok {
+		return false, fmt.Errorf("HaveCounterAbove expects a metrics.Metrics, got %T", actual)
+	}
+	m.total = 0
+	for _, sample := range data[m.name] {
+		m.total += float64(sample.Value)
+	}
+	return m.total > m.threshold, nil
+}
+
+func (m *haveCounterAboveMatcher) FailureMessage(actual interface{}) string {
+	return fmt.Sprintf("Expected %q to be above %v, got %v", m.name, m.threshold, m.total)
+}
+
+func (m *haveCounterAboveMatcher) NegatedFailureMessage(actual interface{}) string {
+	return fmt.Sprintf("Expected %q not to be above %v, got %v", m.name, m.threshold, m.total)
+}
+
+// BeWithinSLO succeeds if actual (a model.Samples snapshot of a histogram or
+// summary family) has a sample at the given quantile (e.g. "0.99") whose
+// value, scaled by unit (e.g. time.Second for a family reporting seconds),
+// is no greater than threshold. source names where the threshold came from
+// (e.g. "SLOConfig PodStartup tier") so a failure is self-explanatory
+// without cross-referencing slo_config.go, and the failure message includes
+// every sample in the snapshot so the shape of the distribution -- not just
+// the one quantile that tripped -- is visible without rerunning the test.
+func BeWithinSLO(quantile string, threshold time.Duration, unit time.Duration, source string) types.GomegaMatcher {
+	return &beWithinSLOMatcher{quantile: quantile, threshold: threshold, unit: unit, source: source}
+}
+
+type beWithinSLOMatcher struct {
+	quantile  string
+	threshold time.Duration
+	unit      time.Duration
+	source    string
+
+	found bool
+	value time.Duration
+}
+
+func (m *beWithinSLOMatcher) Match(actual interface{}) (bool, error) {
+	samples, ok := actual.(model.Samples)
+	if !ok {
+		return false, fmt.Errorf("BeWithinSLO expects a model.Samples, got %T", actual)
+	}
+	raw, ok := metrics.ValueAtQuantile(samples, m.quantile)
+	m.found = ok
+	if !ok {
+		return false, nil
+	}
+	m.value = time.Duration(raw * float64(m.unit))
+	return m.value <= m.threshold, nil
+}
+
+func (m *beWithinSLOMatcher) FailureMessage(actual interface{}) string {
+	samples := actual.(model.Samples)
+	if !m.found {
+		return fmt.Sprintf("Expected a sample at quantile %q but found none among %d sample(s):\n%v",
+			m.quantile, len(samples), metrics.FormatTopSamples(samples, len(samples)))
+	}
+	return fmt.Sprintf("Expected %vth-percentile latency %v to be within SLO threshold %v (source: %v); full distribution:\n%v",
+		m.quantile, m.value, m.threshold, m.source, metrics.FormatTopSamples(samples, len(samples)))
+}
+
+func (m *beWithinSLOMatcher) NegatedFailureMessage(actual interface{}) string {
+	samples := actual.(model.Samples)
+	return fmt.Sprintf("Expected %vth-percentile latency %v not to be within SLO threshold %v (source: %v); full distribution:\n%v",
+		m.quantile, m.value, m.threshold, m.source, metrics.FormatTopSamples(samples, len(samples)))
+}
+
+// sampleMatchesLabels returns true if sample carries every label/value pair
+// in labels (it may also carry other labels not mentioned).
+func sampleMatchesLabels(sample *model.Sample, labels map[string]string) bool {
+	for k, v := range labels {
+		if string(sample.Metric[model.LabelName(k)]) != v {
+			return false
+		}
+	}
+	return true
+}
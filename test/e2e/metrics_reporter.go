@@ -0,0 +1,76 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"strings"
+
+	"github.com/onsi/ginkgo/config"
+	"github.com/onsi/ginkgo/types"
+	"github.com/prometheus/common/model"
+
+	"k8s.io/kubernetes/pkg/metrics"
+)
+
+// TestMetricsReporter is a Ginkgo reporter that records each spec's wall time
+// and pass/fail outcome as Prometheus samples, reusing pkg/metrics's
+// serialization so CI dashboards can be built from the suite's own
+// telemetry the same way they are from component metrics.
+type TestMetricsReporter struct {
+	result metrics.Metrics
+}
+
+// NewTestMetricsReporter creates a TestMetricsReporter ready to be passed to
+// ginkgo.RunSpecsWithDefaultAndCustomReporters.
+func NewTestMetricsReporter() *TestMetricsReporter {
+	return &TestMetricsReporter{result: make(metrics.Metrics)}
+}
+
+func (r *TestMetricsReporter) SpecSuiteWillBegin(config config.GinkgoConfigType, summary *types.SuiteSummary) {
+}
+
+func (r *TestMetricsReporter) BeforeSuiteDidRun(setupSummary *types.SetupSummary) {}
+
+func (r *TestMetricsReporter) SpecWillRun(specSummary *types.SpecSummary) {}
+
+func (r *TestMetricsReporter) SpecDidComplete(specSummary *types.SpecSummary) {
+	metric := model.Metric{"name": model.LabelValue(strings.Join(specSummary.ComponentTexts[1:], " "))}
+
+	passed := model.SampleValue(0)
+	if specSummary.State == types.SpecStatePassed {
+		passed = 1
+	}
+
+	r.result["e2e_test_duration_seconds"] = append(r.result["e2e_test_duration_seconds"], &model.Sample{
+		Metric: metric,
+		Value:  model.SampleValue(specSummary.RunTime.Seconds()),
+	})
+	r.result["e2e_test_passed"] = append(r.result["e2e_test_passed"], &model.Sample{
+		Metric: metric,
+		Value:  passed,
+	})
+}
+
+func (r *TestMetricsReporter) AfterSuiteDidRun(setupSummary *types.SetupSummary) {}
+
+func (r *TestMetricsReporter) SpecSuiteDidEnd(summary *types.SuiteSummary) {
+	r.result["e2e_suite_passed_total"] = model.Samples{{Value: model.SampleValue(summary.NumberOfPassedSpecs)}}
+	r.result["e2e_suite_failed_total"] = model.Samples{{Value: model.SampleValue(summary.NumberOfFailedSpecs)}}
+	r.result["e2e_suite_duration_seconds"] = model.Samples{{Value: model.SampleValue(summary.RunTime.Seconds())}}
+
+	Logf("e2e suite metrics:\n%s", r.result.String())
+}
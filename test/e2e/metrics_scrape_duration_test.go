@@ -0,0 +1,46 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"time"
+
+	"k8s.io/kubernetes/pkg/metrics"
+
+	. "github.com/onsi/ginkgo"
+)
+
+// apiServerScrapeBudget is how long the apiserver's /metrics endpoint may
+// take to respond. Production has seen slow metrics handlers cause
+// Prometheus scrape timeouts; this keeps that regression from going
+// unnoticed until it shows up there.
+const apiServerScrapeBudget = 5 * time.Second
+
+var _ = Describe("Metrics scrape duration", func() {
+	framework := NewFramework("metrics-scrape-duration")
+
+	It("should serve apiserver metrics within the scrape duration budget", func() {
+		options := metrics.DefaultMetricsGrabberOptions()
+		options.ScrapeDurationBudgets = map[string]time.Duration{
+			metrics.ComponentApiServer: apiServerScrapeBudget,
+		}
+		grabber, err := metrics.NewMetricsGrabber(framework.Client, false, false, false, true, options)
+		expectNoError(err)
+		_, err = grabber.GrabFromApiServer(nil)
+		expectNoError(err)
+	})
+})
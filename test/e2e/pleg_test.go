@@ -0,0 +1,67 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"time"
+
+	"k8s.io/kubernetes/pkg/metrics"
+	"k8s.io/kubernetes/pkg/util/sets"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// maxRelistIntervalP99 is the highest acceptable p99 PLEG relist interval
+// during pod churn. PLEG stalls currently only surface as NodeNotReady much
+// later, so this catches the regression at its source.
+const maxRelistIntervalP99 = 5 * time.Second
+
+var _ = Describe("Kubelet PLEG relist latency", func() {
+	f := NewFramework("pleg-relist-latency")
+
+	It("should keep PLEG relist interval bounded during pod churn", func() {
+		if !SupportsKubeletProxyScrape() {
+			Skipf("Provider does not support kubelet proxy scraping.")
+		}
+		nodes := ListSchedulableNodesOrDie(f.Client)
+		Expect(nodes.Items).NotTo(BeEmpty())
+		nodeName := nodes.Items[0].Name
+
+		config := RCConfig{
+			Client:    f.Client,
+			Name:      "pleg-churn",
+			Namespace: f.Namespace.Name,
+			Image:     "gcr.io/google_containers/pause:2.0",
+			Replicas:  20,
+		}
+		expectNoError(RunRC(config))
+		expectNoError(DeleteRC(f.Client, f.Namespace.Name, config.Name))
+
+		grabber, err := metrics.NewMetricsGrabber(f.Client, true, false, false, false)
+		expectNoError(err)
+		response, err := grabber.GrabFromKubelet(nodeName, sets.NewString())
+		expectNoError(err)
+
+		p99, ok := metrics.ValueAtQuantile(response.RelistInterval(), "0.99")
+		if !ok {
+			Skipf("No kubelet_pleg_relist_interval_microseconds p99 sample observed.")
+		}
+		Logf("PLEG relist interval p99 on %v: %v microseconds", nodeName, p99)
+		Expect(time.Duration(p99) * time.Microsecond).To(BeNumerically("<", maxRelistIntervalP99))
+	})
+})
@@ -0,0 +1,66 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import "strings"
+
+// providerCapabilities describes what a cloud provider actually supports,
+// so tests can skip based on the capability they need rather than on a
+// provider name list that constantly goes stale as new providers are added
+// or existing ones change behavior.
+type providerCapabilities struct {
+	// supportsKubeletProxyScrape is true when the apiserver can proxy a
+	// request straight to a node's kubelet (e.g. for /metrics).
+	supportsKubeletProxyScrape bool
+	// hasRegisteredMaster is true when the master runs as a regular,
+	// listable Node rather than being hidden from the API.
+	hasRegisteredMaster bool
+	// supportsSSHToMaster is true when tests can fall back to SSHing into
+	// the master host directly.
+	supportsSSHToMaster bool
+}
+
+// knownProviderCapabilities holds the capabilities of each provider this
+// suite knows how to drive. Providers not listed get the zero value (no
+// capabilities), which fails safe into skipping rather than assuming.
+var knownProviderCapabilities = map[string]providerCapabilities{
+	"gce": {supportsKubeletProxyScrape: true, hasRegisteredMaster: true, supportsSSHToMaster: true},
+	"gke": {supportsKubeletProxyScrape: false, hasRegisteredMaster: false, supportsSSHToMaster: false},
+	"aws": {supportsKubeletProxyScrape: true, hasRegisteredMaster: true, supportsSSHToMaster: true},
+}
+
+func currentProviderCapabilities() providerCapabilities {
+	return knownProviderCapabilities[strings.ToLower(testContext.Provider)]
+}
+
+// SupportsKubeletProxyScrape reports whether the current provider allows the
+// apiserver to proxy requests directly to a node's kubelet.
+func SupportsKubeletProxyScrape() bool {
+	return currentProviderCapabilities().supportsKubeletProxyScrape
+}
+
+// HasRegisteredMaster reports whether the current provider registers its
+// master as a listable Node.
+func HasRegisteredMaster() bool {
+	return currentProviderCapabilities().hasRegisteredMaster
+}
+
+// SupportsSSHToMaster reports whether the current provider allows tests to
+// fall back to SSHing into the master host directly.
+func SupportsSSHToMaster() bool {
+	return currentProviderCapabilities().supportsSSHToMaster
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/onsi/ginkgo/config"
+	"github.com/onsi/ginkgo/types"
+	"github.com/prometheus/common/model"
+
+	client "k8s.io/kubernetes/pkg/client/unversioned"
+	"k8s.io/kubernetes/pkg/metrics"
+)
+
+// runtimeErrorBudget is how many additional docker/runtime operation errors,
+// per node per operation, a suite run may accumulate before
+// RuntimeErrorRateReporter treats it as a regression rather than noise.
+const runtimeErrorBudget = 5
+
+// RuntimeErrorRateReporter is a Ginkgo reporter that snapshots every node's
+// kubelet docker/runtime operation error counters before and after a suite
+// run and warns loudly if any node/operation pair grew by more than
+// runtimeErrorBudget, so a runtime regression introduced by the suite
+// doesn't get lost in a sea of per-test flakiness.
+type RuntimeErrorRateReporter struct {
+	client *client.Client
+	before map[string]map[string]float64
+
+	// beforePodStarts is the cluster-wide total of kubelet_pod_start_latency
+	// samples (i.e. pods started) across all kubelets at suite start, so
+	// SpecSuiteDidEnd can report how many pods the suite started overall
+	// without a reader having to add up the per-node breakdown by hand.
+	beforePodStarts float64
+}
+
+// NewRuntimeErrorRateReporter creates a RuntimeErrorRateReporter ready to be
+// passed to ginkgo.RunSpecsWithDefaultAndCustomReporters.
+func NewRuntimeErrorRateReporter(c *client.Client) *RuntimeErrorRateReporter {
+	return &RuntimeErrorRateReporter{client: c}
+}
+
+func (r *RuntimeErrorRateReporter) SpecSuiteWillBegin(config config.GinkgoConfigType, summary *types.SuiteSummary) {
+	r.before = r.snapshot()
+	r.beforePodStarts = r.totalPodStarts()
+}
+
+func (r *RuntimeErrorRateReporter) BeforeSuiteDidRun(setupSummary *types.SetupSummary) {}
+
+func (r *RuntimeErrorRateReporter) SpecWillRun(specSummary *types.SpecSummary) {}
+
+func (r *RuntimeErrorRateReporter) SpecDidComplete(specSummary *types.SpecSummary) {}
+
+func (r *RuntimeErrorRateReporter) AfterSuiteDidRun(setupSummary *types.SetupSummary) {}
+
+func (r *RuntimeErrorRateReporter) SpecSuiteDidEnd(summary *types.SuiteSummary) {
+	after := r.snapshot()
+	var regressions []string
+	for node, afterOps := range after {
+		for op, afterCount := range afterOps {
+			delta := afterCount - r.before[node][op]
+			if delta > runtimeErrorBudget {
+				regressions = append(regressions, fmt.Sprintf("  %s/%s: +%.0f", node, op, delta))
+			}
+		}
+	}
+	totalPodStarts := r.totalPodStarts() - r.beforePodStarts
+	Logf("Cluster started %.0f pods across all kubelets during this suite run", totalPodStarts)
+
+	if len(regressions) > 0 {
+		Logf("WARNING: runtime operation error counters increased beyond the %d-error budget during this suite run:\n%s", runtimeErrorBudget, strings.Join(regressions, "\n"))
+	}
+}
+
+// snapshot grabs every node's kubelet_docker_errors counters, broken down by
+// node and operation_type. Grab failures are logged, not fatal, since a
+// single unreachable kubelet shouldn't prevent reporting on the rest.
+func (r *RuntimeErrorRateReporter) snapshot() map[string]map[string]float64 {
+	result := make(map[string]map[string]float64)
+	grabber, err := metrics.NewMetricsGrabber(r.client, true, false, false, false)
+	if err != nil {
+		logGrabFailure("checking runtime operation error rates", err)
+		return result
+	}
+	grabber.SetParallelism(testContext.MetricsGrabParallelism)
+	grabber.SetKubeletScrapeRateLimit(float32(testContext.KubeletScrapeQPS), testContext.KubeletScrapeBurst)
+	allMetrics, err := grabber.GrabFromAllKubelets(nil)
+	if err != nil {
+		logGrabFailure("checking runtime operation error rates", err)
+	}
+	for node, m := range allMetrics {
+		ops := make(map[string]float64)
+		for _, sample := range m.DockerErrorsCount() {
+			ops[string(sample.Metric["operation_type"])] = float64(sample.Value)
+		}
+		result[node] = ops
+	}
+	return result
+}
+
+// totalPodStarts grabs every node's kubelet_pod_start_latency sample count
+// and sums them into one cluster-wide total. Like snapshot, a grab failure
+// is logged, not fatal, since a single unreachable kubelet shouldn't
+// prevent reporting on the rest.
+func (r *RuntimeErrorRateReporter) totalPodStarts() float64 {
+	grabber, err := metrics.NewMetricsGrabber(r.client, true, false, false, false)
+	if err != nil {
+		logGrabFailure("checking cluster-wide pod start count", err)
+		return 0
+	}
+	grabber.SetParallelism(testContext.MetricsGrabParallelism)
+	grabber.SetKubeletScrapeRateLimit(float32(testContext.KubeletScrapeQPS), testContext.KubeletScrapeBurst)
+	allMetrics, err := grabber.GrabFromAllKubelets(nil)
+	if err != nil {
+		logGrabFailure("checking cluster-wide pod start count", err)
+	}
+	perNode := make(map[string]model.Samples, len(allMetrics))
+	for node, m := range allMetrics {
+		perNode[node] = m.PodStartCount()
+	}
+	return metrics.SumAcrossInstances(perNode)
+}
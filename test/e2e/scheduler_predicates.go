@@ -25,12 +25,19 @@ import (
 	"k8s.io/kubernetes/pkg/api/unversioned"
 	client "k8s.io/kubernetes/pkg/client/unversioned"
 	"k8s.io/kubernetes/pkg/fields"
+	"k8s.io/kubernetes/pkg/metrics"
 	"k8s.io/kubernetes/pkg/util"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
 
+// kubeletSyncLoopLatencyThreshold is the node-health p99 threshold for the
+// kubelet's sync loop duration: sync loop stalls past this point are a
+// leading indicator of NodeNotReady flakes, well before the node controller
+// itself would notice a missed heartbeat.
+const kubeletSyncLoopLatencyThreshold = 5 * time.Second
+
 // Returns a number of currently scheduled and not scheduled Pods.
 func getPodsScheduled(pods *api.PodList) (scheduledPods, notScheduledPods []api.Pod) {
 	for _, pod := range pods.Items {
@@ -260,6 +267,24 @@ var _ = Describe("SchedulerPredicates [Serial]", func() {
 		time.Sleep(10 * time.Second)
 
 		verifyResult(c, podName, ns)
+
+		By("Checking that kubelet sync loop latency stayed within the node-health threshold while the cluster was saturated")
+		grabber, err := metrics.NewMetricsGrabber(c, true, false, false, false)
+		expectNoError(err)
+		allKubeletMetrics, err := grabber.GrabFromAllKubelets(nil)
+		expectNoError(err)
+		for node, kubeletMetrics := range allKubeletMetrics {
+			samples := kubeletMetrics.SyncPodsLatency()
+			if len(samples) == 0 {
+				Logf("No sync loop latency samples from node %v yet; skipping", node)
+				continue
+			}
+			p99, ok := metrics.ValueAtQuantile(samples, "0.99")
+			recordSLOResult(fmt.Sprintf("KubeletSyncLoopLatency[%s]", node), ok && time.Duration(p99)*time.Microsecond <= kubeletSyncLoopLatencyThreshold,
+				"p99=%v (threshold %v, source: node-health threshold)", time.Duration(p99)*time.Microsecond, kubeletSyncLoopLatencyThreshold)
+			Expect(samples).To(BeWithinSLO("0.99", kubeletSyncLoopLatencyThreshold, time.Microsecond, "node-health threshold"), "node %v", node)
+		}
+
 		cleanupPods(c, ns)
 	})
 
@@ -453,7 +478,7 @@ var _ = Describe("SchedulerPredicates [Serial]", func() {
 				},
 				NodeSelector: map[string]string{
 					"kubernetes.io/hostname": nodeName,
-					k: v,
+					k:                        v,
 				},
 			},
 		})
@@ -0,0 +1,58 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"k8s.io/kubernetes/pkg/metrics"
+	"k8s.io/kubernetes/pkg/util/sets"
+	_ "k8s.io/kubernetes/plugin/pkg/scheduler/algorithmprovider"
+	"k8s.io/kubernetes/plugin/pkg/scheduler/factory"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Scheduler predicate/priority metrics", func() {
+	f := NewFramework("scheduler-predicate-metrics")
+
+	It("should only report evaluation latency for registered predicates and priorities", func() {
+		grabber, err := metrics.NewMetricsGrabber(f.Client, false, true, false, false)
+		expectNoError(err)
+		SkipUnlessComponentExposesMetrics(grabber, "scheduler")
+
+		config := RCConfig{
+			Client:    f.Client,
+			Name:      "scheduler-metrics-probe",
+			Namespace: f.Namespace.Name,
+			Image:     "gcr.io/google_containers/pause:2.0",
+			Replicas:  1,
+		}
+		expectNoError(RunRC(config))
+
+		response, err := grabber.GrabFromScheduler(sets.NewString())
+		expectNoError(err)
+
+		for _, sample := range response.PredicateEvaluationLatency() {
+			name := string(sample.Metric["predicate"])
+			Expect(factory.IsFitPredicateRegistered(name)).To(BeTrue(), "unexpected predicate label %q", name)
+		}
+		for _, sample := range response.PriorityEvaluationLatency() {
+			name := string(sample.Metric["priority"])
+			Expect(factory.IsPriorityFunctionRegistered(name)).To(BeTrue(), "unexpected priority label %q", name)
+		}
+	})
+})
@@ -0,0 +1,71 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"time"
+
+	"k8s.io/kubernetes/pkg/metrics"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// minSchedulingThroughput is the lowest acceptable rate, in pods scheduled
+// per second, during the burst below. It's intentionally conservative; the
+// point is to catch a scheduler regression, not to set a performance bar.
+const minSchedulingThroughput = 5.0
+
+var _ = Describe("Scheduler throughput [Feature:Performance]", func() {
+	f := NewFramework("scheduler-throughput")
+
+	It("should schedule a burst of pods at an acceptable rate [sig-scalability]", func() {
+		const numPods = 500
+
+		grabber, err := metrics.NewMetricsGrabber(f.Client, false, false, true, false)
+		expectNoError(err)
+
+		sampler := metrics.NewSampler(2*time.Second, func() (metrics.Metrics, error) {
+			m, err := grabber.GrabFromScheduler(nil)
+			return metrics.Metrics(m), err
+		})
+		sampler.Start()
+
+		config := RCConfig{
+			Client:    f.Client,
+			Name:      "scheduler-throughput",
+			Namespace: f.Namespace.Name,
+			Image:     "gcr.io/google_containers/pause:2.0",
+			Replicas:  numPods,
+		}
+		expectNoError(RunRC(config))
+
+		samples := sampler.Stop()
+		Expect(len(samples)).To(BeNumerically(">=", 2), "need at least two samples to compute throughput")
+
+		first, last := samples[0], samples[len(samples)-1]
+		elapsed := last.Time.Sub(first.Time).Seconds()
+		Expect(elapsed).To(BeNumerically(">", 0))
+
+		startCount := metrics.SumValues(metrics.SchedulerMetrics(first.Metrics).SchedulingCount())
+		endCount := metrics.SumValues(metrics.SchedulerMetrics(last.Metrics).SchedulingCount())
+		throughput := (endCount - startCount) / elapsed
+
+		Logf("Scheduler throughput during burst: %.2f pods/sec (%v samples over %.1fs)", throughput, len(samples), elapsed)
+		Expect(throughput).To(BeNumerically(">=", minSchedulingThroughput))
+	})
+})
@@ -32,6 +32,7 @@ import (
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/api/errors"
 	client "k8s.io/kubernetes/pkg/client/unversioned"
+	"k8s.io/kubernetes/pkg/metrics"
 	"k8s.io/kubernetes/pkg/types"
 	"k8s.io/kubernetes/pkg/util"
 	"k8s.io/kubernetes/pkg/util/intstr"
@@ -480,6 +481,7 @@ var _ = Describe("Services", func() {
 		// Wait for the load balancer to be created asynchronously
 		service, err = waitForLoadBalancerIngress(f.Client, serviceName, f.Namespace.Name)
 		Expect(err).NotTo(HaveOccurred())
+		checkCloudProviderAPIMetrics(f.Client)
 
 		if service.Spec.Type != api.ServiceTypeLoadBalancer {
 			Failf("got unexpected Spec.Type for LoadBalancer service: %v", service)
@@ -909,6 +911,26 @@ func updateService(c *client.Client, namespace, serviceName string, update func(
 	return service, err
 }
 
+// checkCloudProviderAPIMetrics asserts that the service controller recorded
+// a successful EnsureLoadBalancer call while provisioning the load balancer
+// above, so a creation timeout reads as a controller bug rather than the
+// cloud provider being slow or erroring. It polls rather than grabbing once,
+// since the controller-manager's Prometheus handler may not have observed
+// the call yet the instant waitForLoadBalancerIngress returns. Best-effort:
+// a grabber creation failure is logged, not fatal, since the LoadBalancer
+// assertions above already cover behavior.
+func checkCloudProviderAPIMetrics(c *client.Client) {
+	grabber, err := metrics.NewMetricsGrabber(c, false, false, true, false)
+	if err != nil {
+		logGrabFailure("checking cloud provider API metrics", err)
+		return
+	}
+	err = grabber.WaitForMetric(metrics.ComponentControllerManager, func(m metrics.Metrics) bool {
+		return metrics.SumValues(metrics.ControllerManagerMetrics(m).CloudProviderAPILatencyCount()) > 0
+	}, 1*time.Minute)
+	Expect(err).NotTo(HaveOccurred(), "expected the service controller to record a cloud provider API call")
+}
+
 func waitForLoadBalancerIngress(c *client.Client, serviceName, namespace string) (*api.Service, error) {
 	// TODO: once support ticket 21807001 is resolved, reduce this timeout back to something reasonable
 	const timeout = 20 * time.Minute
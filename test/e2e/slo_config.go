@@ -0,0 +1,123 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"k8s.io/kubernetes/pkg/api/unversioned"
+
+	"github.com/ghodss/yaml"
+)
+
+// NodeCountThreshold is one tier of a latency SLO: the percentile
+// thresholds that apply to clusters of up to MaxNodes nodes. MaxNodes of 0
+// is the catch-all tier for cluster sizes larger than any other tier in the
+// list. Tiers are evaluated in order, mirroring the small/medium/large
+// cutoffs this suite has always used, just made data-driven.
+type NodeCountThreshold struct {
+	MaxNodes int                  `json:"maxNodes,omitempty"`
+	Perc50   unversioned.Duration `json:"perc50,omitempty"`
+	Perc90   unversioned.Duration `json:"perc90,omitempty"`
+	Perc99   unversioned.Duration `json:"perc99,omitempty"`
+}
+
+// SLOConfig is the schema of the file pointed to by --slo-config-file. Each
+// field is a list of NodeCountThreshold tiers for one SLO verification
+// helper, so a 1000-node CI job and a 100-node CI job can run the exact
+// same verification code against two different config files rather than
+// two different sets of Go constants.
+type SLOConfig struct {
+	PodStartup      []NodeCountThreshold `json:"podStartup"`
+	ListPodsAPICall []NodeCountThreshold `json:"listPodsAPICall"`
+	APICall         []NodeCountThreshold `json:"apiCall"`
+}
+
+func durationOf(d time.Duration) unversioned.Duration {
+	return unversioned.Duration{Duration: d}
+}
+
+// defaultSLOConfig reproduces the thresholds this suite used before
+// --slo-config-file existed, so a run that doesn't pass the flag behaves
+// exactly as before.
+func defaultSLOConfig() *SLOConfig {
+	return &SLOConfig{
+		PodStartup: []NodeCountThreshold{
+			{Perc50: durationOf(podStartupThreshold), Perc90: durationOf(podStartupThreshold), Perc99: durationOf(podStartupThreshold)},
+		},
+		ListPodsAPICall: []NodeCountThreshold{
+			{MaxNodes: 250, Perc99: durationOf(listPodLatencySmallThreshold)},
+			{MaxNodes: 500, Perc99: durationOf(listPodLatencyMediumThreshold)},
+			{Perc99: durationOf(listPodLatencyLargeThreshold)},
+		},
+		APICall: []NodeCountThreshold{
+			{MaxNodes: 250, Perc99: durationOf(apiCallLatencySmallThreshold)},
+			{MaxNodes: 500, Perc99: durationOf(apiCallLatencyMediumThreshold)},
+			{Perc99: durationOf(apiCallLatencyLargeThreshold)},
+		},
+	}
+}
+
+// LoadSLOConfig reads and parses the SLO threshold config at path. An empty
+// path returns the suite's built-in defaults, so --slo-config-file stays
+// optional.
+func LoadSLOConfig(path string) (*SLOConfig, error) {
+	if path == "" {
+		return defaultSLOConfig(), nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config := &SLOConfig{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// tierFor returns the tier in tiers that applies to a cluster of numNodes
+// nodes: the first tier whose MaxNodes is 0 (catch-all) or >= numNodes.
+func tierFor(tiers []NodeCountThreshold, numNodes int) NodeCountThreshold {
+	for _, tier := range tiers {
+		if tier.MaxNodes == 0 || numNodes <= tier.MaxNodes {
+			return tier
+		}
+	}
+	return NodeCountThreshold{}
+}
+
+var (
+	sloConfigOnce sync.Once
+	sloConfig     *SLOConfig
+)
+
+// getSLOConfig lazily loads testContext.SLOConfigFile the first time an SLO
+// verification helper needs it, so it's read after flags are parsed rather
+// than at package init time.
+func getSLOConfig() *SLOConfig {
+	sloConfigOnce.Do(func() {
+		config, err := LoadSLOConfig(testContext.SLOConfigFile)
+		if err != nil {
+			Failf("Could not load SLO config from %q: %v", testContext.SLOConfigFile, err)
+		}
+		sloConfig = config
+	})
+	return sloConfig
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+
+	"github.com/onsi/ginkgo/config"
+	"github.com/onsi/ginkgo/types"
+)
+
+// SLOResult is one recorded outcome of a metric-based SLO or invariant
+// check, e.g. "pod startup latency stayed under its threshold".
+type SLOResult struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// SLOReporter is a Ginkgo reporter that collects SLOResults recorded by
+// SLO-verifying helpers (VerifyPodStartupLatency, checkNamespaceDeletionLatency,
+// etc.) throughout a suite run and, at the end, prints a single consolidated
+// pass/fail table and writes it as a JSON artifact -- so performance signal
+// that's otherwise scattered across dozens of It blocks has one place to
+// look.
+type SLOReporter struct {
+	outputDir string
+
+	lock    sync.Mutex
+	results []SLOResult
+}
+
+// NewSLOReporter creates an SLOReporter that writes its report under
+// outputDir, ready to be passed to ginkgo.RunSpecsWithDefaultAndCustomReporters.
+func NewSLOReporter(outputDir string) *SLOReporter {
+	return &SLOReporter{outputDir: outputDir}
+}
+
+// Record appends one SLO check's outcome to the report. detail is a short
+// human-readable explanation, typically the measured value compared against
+// its threshold.
+func (r *SLOReporter) Record(name string, passed bool, detail string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.results = append(r.results, SLOResult{Name: name, Passed: passed, Detail: detail})
+}
+
+func (r *SLOReporter) SpecSuiteWillBegin(config config.GinkgoConfigType, summary *types.SuiteSummary) {
+}
+func (r *SLOReporter) BeforeSuiteDidRun(setupSummary *types.SetupSummary) {}
+func (r *SLOReporter) SpecWillRun(specSummary *types.SpecSummary)         {}
+func (r *SLOReporter) SpecDidComplete(specSummary *types.SpecSummary)     {}
+func (r *SLOReporter) AfterSuiteDidRun(setupSummary *types.SetupSummary)  {}
+
+func (r *SLOReporter) SpecSuiteDidEnd(summary *types.SuiteSummary) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if len(r.results) == 0 {
+		return
+	}
+
+	passed := 0
+	Logf("SLO summary: %d check(s) recorded", len(r.results))
+	for _, result := range r.results {
+		status := "PASS"
+		if !result.Passed {
+			status = "FAIL"
+		} else {
+			passed++
+		}
+		Logf("  [%v] %v: %v", status, result.Name, result.Detail)
+	}
+	Logf("SLO summary: %d/%d passed", passed, len(r.results))
+
+	data, err := json.MarshalIndent(r.results, "", "  ")
+	if err != nil {
+		Logf("Could not marshal SLO report: %v", err)
+		return
+	}
+	path := filepath.Join(r.outputDir, "slo_results.json")
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		Logf("Could not write SLO report to %v: %v", path, err)
+		return
+	}
+	Logf("Wrote %d SLO result(s) to %v", len(r.results), path)
+}
+
+// sloReporter is the suite-wide SLOReporter instance wired up in e2e_test.go.
+// It is nil when running specs outside the top-level TestE2E entrypoint (e.g.
+// unit tests in this package), so callers must guard with a nil check before
+// recording.
+var sloReporter *SLOReporter
+
+// recordSLOResult records a pass/fail outcome against the suite-wide
+// SLOReporter, formatting detail the same way Logf does. It is a no-op if
+// no SLOReporter is wired up for this run.
+func recordSLOResult(name string, passed bool, detailFormat string, args ...interface{}) {
+	if sloReporter == nil {
+		return
+	}
+	sloReporter.Record(name, passed, fmt.Sprintf(detailFormat, args...))
+}
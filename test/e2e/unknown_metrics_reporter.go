@@ -0,0 +1,129 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/onsi/ginkgo/config"
+	"github.com/onsi/ginkgo/types"
+
+	"k8s.io/kubernetes/pkg/util/sets"
+)
+
+// UnknownMetricRecord is one triage queue entry: a metric or label this
+// suite doesn't recognize, observed on the given component at the given
+// build, so curating the known-metrics maps is a matter of working this
+// list instead of grepping ginkgo output for "WARNING: grabbed metrics...".
+// ObservingTests collects every spec that hit this same component+metric
+// this run, so one new component metric shows up as a single record with
+// several observers instead of a record per spec.
+type UnknownMetricRecord struct {
+	Component      string   `json:"component"`
+	Metric         string   `json:"metric"`
+	Labels         []string `json:"labels,omitempty"`
+	FirstSeenBuild string   `json:"firstSeenBuild"`
+	ObservingTests []string `json:"observingTests"`
+}
+
+// UnknownMetricsReporter is a Ginkgo reporter that accumulates
+// UnknownMetricRecords reported during a suite run and, if any were
+// reported, writes them as a JSON array to "unknown_metrics.json" in the
+// artifacts directory at suite end.
+type UnknownMetricsReporter struct {
+	outputDir string
+
+	lock    sync.Mutex
+	index   map[string]int // component+"/"+metric -> index into records
+	records []UnknownMetricRecord
+}
+
+// NewUnknownMetricsReporter creates an UnknownMetricsReporter that writes
+// its report under outputDir, ready to be passed to
+// ginkgo.RunSpecsWithDefaultAndCustomReporters.
+func NewUnknownMetricsReporter(outputDir string) *UnknownMetricsReporter {
+	return &UnknownMetricsReporter{outputDir: outputDir, index: make(map[string]int)}
+}
+
+// RecordUnknownMetric adds an unrecognized metric name to the report, or
+// folds testName into the existing record's ObservingTests if component+
+// metric was already recorded this run. It returns whether this is the
+// metric's first observation this run, so callers can fail only once per
+// component+metric instead of once per observing spec.
+func (r *UnknownMetricsReporter) RecordUnknownMetric(component, metric, build, testName string) bool {
+	return r.record(UnknownMetricRecord{Component: component, Metric: metric, FirstSeenBuild: build}, testName)
+}
+
+// RecordUnknownLabels adds a metric's unrecognized labels to the report, or
+// folds testName into the existing record's ObservingTests if component+
+// metric was already recorded this run. It returns whether this is the
+// metric's first observation this run.
+func (r *UnknownMetricsReporter) RecordUnknownLabels(component, metric string, labels sets.String, build, testName string) bool {
+	if labels.Len() == 0 {
+		return false
+	}
+	sorted := labels.List()
+	sort.Strings(sorted)
+	return r.record(UnknownMetricRecord{Component: component, Metric: metric, Labels: sorted, FirstSeenBuild: build}, testName)
+}
+
+func (r *UnknownMetricsReporter) record(rec UnknownMetricRecord, testName string) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	key := rec.Component + "/" + rec.Metric
+	if i, ok := r.index[key]; ok {
+		existing := &r.records[i]
+		if !sets.NewString(existing.ObservingTests...).Has(testName) {
+			existing.ObservingTests = append(existing.ObservingTests, testName)
+		}
+		return false
+	}
+	rec.ObservingTests = []string{testName}
+	r.records = append(r.records, rec)
+	r.index[key] = len(r.records) - 1
+	return true
+}
+
+func (r *UnknownMetricsReporter) SpecSuiteWillBegin(config config.GinkgoConfigType, summary *types.SuiteSummary) {
+}
+func (r *UnknownMetricsReporter) BeforeSuiteDidRun(setupSummary *types.SetupSummary) {}
+func (r *UnknownMetricsReporter) SpecWillRun(specSummary *types.SpecSummary)         {}
+func (r *UnknownMetricsReporter) SpecDidComplete(specSummary *types.SpecSummary)     {}
+func (r *UnknownMetricsReporter) AfterSuiteDidRun(setupSummary *types.SetupSummary)  {}
+
+func (r *UnknownMetricsReporter) SpecSuiteDidEnd(summary *types.SuiteSummary) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if len(r.records) == 0 {
+		return
+	}
+	data, err := json.MarshalIndent(r.records, "", "  ")
+	if err != nil {
+		Logf("Could not marshal unknown metrics report: %v", err)
+		return
+	}
+	path := filepath.Join(r.outputDir, "unknown_metrics.json")
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		Logf("Could not write unknown metrics report to %v: %v", path, err)
+		return
+	}
+	Logf("Wrote %d unknown metric(s)/label(s) to %v", len(r.records), path)
+}
@@ -154,8 +154,58 @@ type TestContextType struct {
 	GatherKubeSystemResourceUsageData bool
 	GatherLogsSizes                   bool
 	GatherMetricsAfterTest            bool
+	// If set to true, the framework will grab a baseline metrics snapshot
+	// before each test and store it on the Framework, giving per-test delta
+	// computations (API calls, errors, memory) a reference point without
+	// every test doing its own bookkeeping.
+	GatherMetricsBeforeTest bool
 	// Currently supported values are 'hr' for human-readable and 'json'. It's a comma separated list.
 	OutputPrintType string
+	// Path to a YAML file overriding the default SLO latency thresholds
+	// (see slo_config.go). Empty means use the suite's built-in defaults.
+	SLOConfigFile string
+	// UnknownMetricsPolicy controls what happens when a scraped component
+	// reports a metric this suite doesn't recognize: "fail" (the default)
+	// treats it as a test failure, "warn" only logs it. Periodic jobs should
+	// use "fail" to catch metric-list drift; presubmits can use "warn" so
+	// curating the metric list doesn't block unrelated PRs.
+	UnknownMetricsPolicy string
+	// InvalidLabelsPolicy controls what happens when a scraped component
+	// reports an unrecognized or malformed metric label: "fail" (the
+	// default) treats it as a test failure, "warn" only logs it.
+	InvalidLabelsPolicy string
+	// SLOPolicy controls what happens when a latency SLO (API call or pod
+	// startup) is breached: "fail" (the default) treats it as a test
+	// failure, "warn" only logs and records it to the SLO report.
+	SLOPolicy string
+	// SanityPolicy controls what happens when a metrics sanity check (e.g. a
+	// cumulative counter going negative or spiking across a component
+	// restart) fails: "fail" (the default) treats it as a test failure,
+	// "warn" only logs it.
+	SanityPolicy string
+	// KubeletMetricsBearerToken, if set, is a bearer token authorized for the
+	// nodes/metrics resource. Its presence switches kubelet metrics scraping
+	// to connect directly to each kubelet's secure port instead of going
+	// through the apiserver's node proxy subresource, so the kubelet metrics
+	// tests can run on providers that lock the proxy subresource down (e.g.
+	// GKE) as long as the caller mints or otherwise supplies such a token.
+	KubeletMetricsBearerToken string
+	// MetricsGrabParallelism bounds how many kubelets a MetricsGrabber
+	// scrapes concurrently. Larger scalability clusters need this above 1
+	// to grab cluster-wide metrics in a reasonable time.
+	MetricsGrabParallelism int
+	// KubeletScrapeQPS, if positive, caps how often a MetricsGrabber will
+	// scrape any single kubelet's metrics endpoint, so the periodic Sampler
+	// plus per-test grabs don't hammer a small test node's kubelet. Zero
+	// (the default) leaves kubelet scraping unthrottled.
+	KubeletScrapeQPS float64
+	// KubeletScrapeBurst is the token-bucket burst size paired with
+	// KubeletScrapeQPS. Ignored when KubeletScrapeQPS is zero.
+	KubeletScrapeBurst int
+	// APIServerMetricsPath overrides the server-relative path used to fetch
+	// the apiserver's own /metrics, for clusters that front the apiserver
+	// with a path-rewriting proxy. Empty uses the unprefixed "/metrics".
+	APIServerMetricsPath string
 }
 
 var testContext TestContextType
@@ -836,7 +886,7 @@ func waitForService(c *client.Client, namespace, name string, exist bool, interv
 	return nil
 }
 
-//waitForServiceEndpointsNum waits until the amount of endpoints that implement service to expectNum.
+// waitForServiceEndpointsNum waits until the amount of endpoints that implement service to expectNum.
 func waitForServiceEndpointsNum(c *client.Client, namespace, serviceName string, expectNum int, interval, timeout time.Duration) error {
 	return wait.Poll(interval, timeout, func() (bool, error) {
 		Logf("Waiting for amount of service:%s endpoints to %d", serviceName, expectNum)
@@ -1054,9 +1104,10 @@ func loadClient() (*client.Client, error) {
 
 // randomSuffix provides a random string to append to pods,services,rcs.
 // TODO: Allow service names to have the same form as names
-//       for pods and replication controllers so we don't
-//       need to use such a function and can instead
-//       use the UUID utility function.
+//
+//	for pods and replication controllers so we don't
+//	need to use such a function and can instead
+//	use the UUID utility function.
 func randomSuffix() string {
 	r := rand.New(rand.NewSource(time.Now().UnixNano()))
 	return strconv.Itoa(r.Int() % 10000)